@@ -0,0 +1,267 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"salary-calc/internal/cli"
+	"salary-calc/internal/config"
+	"salary-calc/internal/exchangerate"
+	"salary-calc/internal/output"
+)
+
+const cacheUsage = "usage: %s cache list|show|purge|refresh|path [flags]"
+
+func runCache(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(cacheUsage, os.Args[0])
+	}
+
+	switch args[0] {
+	case "list":
+		return runCacheList(args[1:])
+	case "show":
+		return runCacheShow(args[1:])
+	case "purge":
+		return runCachePurge(args[1:])
+	case "refresh":
+		return runCacheRefresh(args[1:])
+	case "path":
+		return runCachePath(args[1:])
+	default:
+		return fmt.Errorf("unknown cache command: %s", args[0])
+	}
+}
+
+// openAPI creates the exchange rate client once a cache command has parsed
+// its flags.
+func openAPI(logFlags cli.LogFlags) (*exchangerate.ExchangeRateAPI, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return newAPI(cfg, logFlags)
+}
+
+func runCacheList(args []string) error {
+	fs := flag.NewFlagSet("cache list", flag.ExitOnError)
+	var outputFlags cli.OutputFlags
+	outputFlags.Register(fs)
+	var logFlags cli.LogFlags
+	logFlags.Register(fs)
+	if _, err := cli.ParseArgs(fs, args); err != nil {
+		return err
+	}
+
+	opts, err := outputOptions(outputFlags)
+	if err != nil {
+		return err
+	}
+
+	api, err := openAPI(logFlags)
+	if err != nil {
+		return err
+	}
+
+	cache := api.Cache()
+	entries, err := cache.List()
+	if err != nil {
+		return err
+	}
+
+	location := fmt.Sprintf("%s (%s backend)", cache.Dir(), cache.Backend())
+	fmt.Print(output.FormatCacheList(location, entries, time.Now(), opts))
+	return nil
+}
+
+func runCacheShow(args []string) error {
+	fs := flag.NewFlagSet("cache show", flag.ExitOnError)
+	var outputFlags cli.OutputFlags
+	outputFlags.Register(fs)
+	var logFlags cli.LogFlags
+	logFlags.Register(fs)
+	positional, err := cli.ParseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return fmt.Errorf("usage: %s cache show [BASE]", os.Args[0])
+	}
+
+	opts, err := outputOptions(outputFlags)
+	if err != nil {
+		return err
+	}
+
+	api, err := openAPI(logFlags)
+	if err != nil {
+		return err
+	}
+
+	base := api.Base()
+	if len(positional) == 1 {
+		base = strings.ToUpper(positional[0])
+	}
+
+	data, err := api.Cache().Load(base)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return fmt.Errorf("no cached rates for %s", base)
+	}
+
+	fmt.Print(output.FormatCacheData(data, time.Now(), opts))
+	return nil
+}
+
+func runCachePurge(args []string) error {
+	fs := flag.NewFlagSet("cache purge", flag.ExitOnError)
+	bases := fs.String("base", "", "Comma-separated base currencies to purge (default: all)")
+	olderThan := fs.String("older-than", "", "Purge only rates fetched longer ago than this, e.g. 7d, 2w, 1m")
+	dryRun := fs.Bool("dry-run", false, "List what would be purged without removing anything")
+	var logFlags cli.LogFlags
+	logFlags.Register(fs)
+	if _, err := cli.ParseArgs(fs, args); err != nil {
+		return err
+	}
+
+	api, err := openAPI(logFlags)
+	if err != nil {
+		return err
+	}
+
+	selected := make(map[string]bool)
+	for _, base := range splitList(*bases) {
+		selected[strings.ToUpper(base)] = true
+	}
+
+	var cutoff time.Time
+	if *olderThan != "" {
+		if cutoff, err = cli.ParseLast(*olderThan, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	cache := api.Cache()
+	entries, err := cache.List()
+	if err != nil {
+		return err
+	}
+
+	purged := 0
+	for _, entry := range entries {
+		if len(selected) > 0 && !selected[entry.Base] {
+			continue
+		}
+		if !cutoff.IsZero() {
+			fetched := entry.ModTime
+			if entry.Data != nil {
+				fetched = entry.Data.Timestamp
+			}
+			if fetched.After(cutoff) {
+				continue
+			}
+		}
+
+		if *dryRun {
+			fmt.Printf("Would remove %s\n", entry.Path)
+		} else {
+			if err := cache.Remove(entry.Base); err != nil {
+				return err
+			}
+			fmt.Printf("Removed %s\n", entry.Path)
+		}
+		purged++
+	}
+
+	if purged == 0 {
+		fmt.Println("Nothing to purge.")
+	}
+	return nil
+}
+
+func runCacheRefresh(args []string) error {
+	fs := flag.NewFlagSet("cache refresh", flag.ExitOnError)
+	provider := fs.String("provider", "", "Fetch from this provider only, e.g. exchangerate.host (default: first that succeeds)")
+	background := fs.Bool("background", false, "Run silently and release the refresh lock when done, as started by stale-while-revalidate")
+	ratesFile := fs.String("rates-file", "", "Import rates from a JSON, CSV or ECB XML file, or - for stdin")
+	var logFlags cli.LogFlags
+	logFlags.Register(fs)
+	if _, err := cli.ParseArgs(fs, args); err != nil {
+		return err
+	}
+	if *provider != "" && *ratesFile != "" {
+		return fmt.Errorf("-provider and -rates-file cannot be used together")
+	}
+
+	api, err := openAPI(logFlags)
+	if err != nil {
+		return err
+	}
+
+	if *background {
+		// The process that started this refresh took the lock for it.
+		defer api.ReleaseRefreshLock()
+	}
+
+	api.RequireCurrencies(requiredCurrencies()...)
+	var info *exchangerate.RateInfo
+	if *ratesFile != "" {
+		info, err = api.RefreshFrom(exchangerate.NewFileProvider(*ratesFile))
+	} else {
+		info, err = api.Refresh(*provider)
+	}
+	if err != nil {
+		return err
+	}
+	if *background {
+		return nil
+	}
+
+	if info.Stale {
+		fmt.Printf("Imported %s rates from %s dated %s; they are already older than the cache lifetime and count as expired\n",
+			api.Base(), info.Source, info.Timestamp.UTC().Format("2006-01-02"))
+		return nil
+	}
+	fmt.Printf("Refreshed %s rates from %s, expires %s\n", api.Base(), info.Source, info.ExpiresAt.Format("2006-01-02 15:04:05 UTC"))
+	return nil
+}
+
+func runCachePath(args []string) error {
+	fs := flag.NewFlagSet("cache path", flag.ExitOnError)
+	var logFlags cli.LogFlags
+	logFlags.Register(fs)
+	if _, err := cli.ParseArgs(fs, args); err != nil {
+		return err
+	}
+
+	api, err := openAPI(logFlags)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(api.Cache().Dir())
+	return nil
+}
+
+// startBackgroundRefresh runs "cache refresh -background" as a detached
+// process, so that the refresh completes after this command has exited. The
+// child owns the refresh lock taken by the caller and releases it.
+func startBackgroundRefresh() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(exe, "cache", "refresh", "-background")
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
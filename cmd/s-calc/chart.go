@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"salary-calc/internal/cli"
+	"salary-calc/internal/config"
+	"salary-calc/internal/converter"
+	"salary-calc/internal/exchangerate"
+	"salary-calc/internal/output"
+)
+
+const (
+	chartWidth  = 60
+	chartHeight = 12
+)
+
+func runChart(args []string) error {
+	fs := flag.NewFlagSet("chart", flag.ExitOnError)
+	last := fs.String("last", "90d", "Lookback window, e.g. 30d, 12w, 6m, 1y")
+	offline := fs.Bool("offline", false, "Use only the local rate history")
+	width := fs.Int("width", chartWidth, "Chart width in columns")
+	height := fs.Int("height", chartHeight, "Chart height in rows")
+	var outputFlags cli.OutputFlags
+	outputFlags.Register(fs)
+	var logFlags cli.LogFlags
+	logFlags.Register(fs)
+
+	positional, err := cli.ParseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: %s chart EUR/PLN [-last=90d] [-ascii] [-offline]", os.Args[0])
+	}
+
+	base, quote, err := parseRatePair(positional[0])
+	if err != nil {
+		return err
+	}
+	if *width < 2 || *height < 2 {
+		return fmt.Errorf("-width and -height must be at least 2")
+	}
+
+	opts, err := outputOptions(outputFlags)
+	if err != nil {
+		return err
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from, err := cli.ParseLast(*last, to)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	api, err := newAPI(cfg, logFlags)
+	if err != nil {
+		return err
+	}
+
+	points, err := loadSeries(api, base, quote, from, to, *offline)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(output.FormatChart(base+"/"+quote, points, *width, *height, opts))
+	return nil
+}
+
+// runSalaryChart charts what a fixed salary in the input currency was worth
+// in another currency over the lookback window.
+func runSalaryChart(api *exchangerate.ExchangeRateAPI, input converter.Input, flags *cli.Flags, opts output.Options) error {
+	target, err := converter.ValidateCurrency(strings.ToUpper(flags.Chart))
+	if err != nil {
+		return err
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from, err := cli.ParseLast(flags.Last, to)
+	if err != nil {
+		return err
+	}
+
+	points, err := loadSeries(api, string(input.Currency), string(target), from, to, flags.Offline)
+	if err != nil {
+		return err
+	}
+
+	for i := range points {
+		points[i].Rate *= input.Amount
+	}
+
+	title := fmt.Sprintf("%s/%s in %s", opts.Locale.FormatMoney(input.Amount, string(input.Currency)), strings.ToLower(string(input.Period)), target)
+	fmt.Print(output.FormatChart(title, points, chartWidth, chartHeight, opts))
+	return nil
+}
+
+// loadSeries returns the local rate history, first backfilled from a
+// time-series provider unless offline. A failed backfill is not fatal.
+func loadSeries(api *exchangerate.ExchangeRateAPI, base, quote string, from, to time.Time, offline bool) ([]exchangerate.Point, error) {
+	if !offline {
+		if err := api.Backfill(from, to); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: backfill failed, using local history only: %v\n", err)
+		}
+	}
+	return api.RateHistory(base, quote, from, to, false)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"salary-calc/internal/cli"
+	"salary-calc/internal/config"
+	"salary-calc/internal/exchangerate"
+)
+
+const defaultPrefetchInterval = time.Hour
+
+// runDaemon keeps the rate caches of the configured bases fresh, so that
+// interactive use never waits on the network.
+func runDaemon(args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	basesFlag := fs.String("bases", "", "Comma-separated canonical bases to keep cached (default: config prefetch.bases or S_CALC_BASE)")
+	interval := fs.Duration("interval", 0, "How often to check the caches (default: config prefetch.interval or 1h)")
+	var logFlags cli.LogFlags
+	logFlags.RegisterLevel(fs, "info")
+	if _, err := cli.ParseArgs(fs, args); err != nil {
+		return err
+	}
+
+	api, bases, configured, err := prefetchSetup(*basesFlag, logFlags)
+	if err != nil {
+		return err
+	}
+	if *interval == 0 {
+		*interval = configured
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("-interval must be positive")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger := api.Logger()
+	logger.Info("prefetching", "bases", strings.Join(bases, ","), "interval", *interval)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		// Refresh anything that would expire before the next check.
+		prefetch(api, bases, *interval, false)
+
+		select {
+		case <-ctx.Done():
+			logger.Info("stopping")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func runRatesPrefetch(args []string) error {
+	fs := flag.NewFlagSet("rates prefetch", flag.ExitOnError)
+	basesFlag := fs.String("bases", "", "Comma-separated canonical bases to warm (default: config prefetch.bases or S_CALC_BASE)")
+	margin := fs.Duration("margin", time.Hour, "Refresh caches that expire within this time")
+	force := fs.Bool("force", false, "Refresh even if the cache is fresh")
+	var logFlags cli.LogFlags
+	logFlags.RegisterLevel(fs, "info")
+	if _, err := cli.ParseArgs(fs, args); err != nil {
+		return err
+	}
+
+	api, bases, _, err := prefetchSetup(*basesFlag, logFlags)
+	if err != nil {
+		return err
+	}
+
+	if failed := prefetch(api, bases, *margin, *force); failed > 0 {
+		return fmt.Errorf("%d of %d bases could not be refreshed", failed, len(bases))
+	}
+	return nil
+}
+
+// prefetchSetup resolves the bases to prefetch, from the flag or the config
+// file, and the configured check interval.
+func prefetchSetup(basesFlag string, logFlags cli.LogFlags) (*exchangerate.ExchangeRateAPI, []string, time.Duration, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	api, err := newAPI(cfg, logFlags)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	api.RequireCurrencies(requiredCurrencies()...)
+
+	bases := splitList(basesFlag)
+	if len(bases) == 0 {
+		bases = cfg.Prefetch.Bases
+	}
+	if len(bases) == 0 {
+		bases = []string{api.Base()}
+	}
+	for i, base := range bases {
+		bases[i] = strings.ToUpper(base)
+	}
+
+	interval := defaultPrefetchInterval
+	if cfg.Prefetch.Interval != "" {
+		if interval, err = time.ParseDuration(cfg.Prefetch.Interval); err != nil {
+			return nil, nil, 0, fmt.Errorf("invalid prefetch.interval in config: %s", cfg.Prefetch.Interval)
+		}
+	}
+
+	return api, bases, interval, nil
+}
+
+// prefetch refreshes the canonical rates if they expire within margin, then
+// warms the cache of every other base by rebasing them, so that one fetch
+// serves all bases. It returns how many bases failed.
+func prefetch(api *exchangerate.ExchangeRateAPI, bases []string, margin time.Duration, force bool) int {
+	logger := api.Logger()
+	canonical := api.Base()
+
+	var info *exchangerate.RateInfo
+	var refreshed bool
+	var err error
+	if force {
+		info, err = api.Refresh("")
+		refreshed = err == nil
+	} else {
+		info, refreshed, err = api.Prefetch(margin)
+	}
+	switch {
+	case err != nil:
+		logger.Error("prefetch failed", "base", canonical, "error", err)
+		return len(bases)
+	case refreshed:
+		logger.Info("refreshed", "base", canonical, "source", info.Source, "expires_at", info.ExpiresAt)
+	default:
+		logger.Info("cache fresh", "base", canonical, "expires_at", info.ExpiresAt)
+	}
+
+	failed := 0
+	for _, base := range bases {
+		if base == canonical {
+			continue
+		}
+		info, warmed, err := api.WarmBase(base)
+		switch {
+		case err != nil:
+			logger.Error("prefetch failed", "base", base, "error", err)
+			failed++
+		case warmed:
+			logger.Info("rebased", "base", base, "from", canonical, "expires_at", info.ExpiresAt)
+		default:
+			logger.Info("cache fresh", "base", base, "expires_at", info.ExpiresAt)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,7 @@
+//go:build !linux && !darwin
+
+package main
+
+import "os/exec"
+
+func detach(cmd *exec.Cmd) {}
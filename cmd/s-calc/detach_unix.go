@@ -0,0 +1,14 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts cmd in its own session so that it outlives this process and
+// does not receive the terminal's signals.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
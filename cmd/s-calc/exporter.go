@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"salary-calc/internal/cli"
+	"salary-calc/internal/exchangerate"
+)
+
+// runExporter serves Prometheus metrics about the cached rates and the
+// providers they are fetched from.
+func runExporter(args []string) error {
+	fs := flag.NewFlagSet("exporter", flag.ExitOnError)
+	listen := fs.String("listen", ":9108", "Address to serve /metrics on")
+	basesFlag := fs.String("bases", "", "Comma-separated canonical bases to report (default: config prefetch.bases or S_CALC_BASE)")
+	var logFlags cli.LogFlags
+	logFlags.RegisterLevel(fs, "info")
+	if _, err := cli.ParseArgs(fs, args); err != nil {
+		return err
+	}
+
+	api, bases, _, err := prefetchSetup(*basesFlag, logFlags)
+	if err != nil {
+		return err
+	}
+
+	e := &exporter{api: api, bases: bases}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", e.serveMetrics)
+	server := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdown)
+	}()
+
+	logger := api.Logger()
+	logger.Info("serving metrics", "bases", strings.Join(bases, ","), "address", *listen+"/metrics")
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	logger.Info("stopping")
+	return nil
+}
+
+type exporter struct {
+	// mu serializes scrapes, as each one switches the API between bases.
+	mu    sync.Mutex
+	api   *exchangerate.ExchangeRateAPI
+	bases []string
+}
+
+// serveMetrics reads the rates of every base, fetching them if the cache
+// has expired, and writes them with the API counters in the Prometheus text
+// format.
+func (e *exporter) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	var m metricsWriter
+
+	m.family("s_calc_up", "gauge", "Whether rates for the base could be read (1) or not (0).")
+	type baseRates struct {
+		base  string
+		rates map[string]float64
+		info  *exchangerate.RateInfo
+	}
+	var results []baseRates
+	for _, base := range e.bases {
+		e.api.SetBase(base)
+		rates, info, err := e.api.GetRates(base)
+		if err != nil {
+			e.api.Logger().Error("rates unavailable", "base", base, "error", err)
+			m.sample("s_calc_up", labels("base", base), 0)
+			continue
+		}
+		m.sample("s_calc_up", labels("base", base), 1)
+		results = append(results, baseRates{base, rates, info})
+	}
+
+	m.family("s_calc_rate", "gauge", "Exchange rate, units of quote per unit of base.")
+	for _, r := range results {
+		for _, quote := range sortedKeys(r.rates) {
+			if quote != r.base {
+				m.sample("s_calc_rate", labels("base", r.base, "quote", quote), r.rates[quote])
+			}
+		}
+	}
+
+	m.family("s_calc_rate_age_seconds", "gauge", "Time since the rates were fetched.")
+	for _, r := range results {
+		m.sample("s_calc_rate_age_seconds", labels("base", r.base), now.Sub(r.info.Timestamp).Seconds())
+	}
+
+	m.family("s_calc_rate_stale", "gauge", "Whether the rates are past their expiry (1) or fresh (0).")
+	for _, r := range results {
+		stale := 0.0
+		if r.info.Stale || r.info.Revalidating || now.After(r.info.ExpiresAt) {
+			stale = 1
+		}
+		m.sample("s_calc_rate_stale", labels("base", r.base), stale)
+	}
+
+	stats := e.api.Metrics()
+	m.family("s_calc_cache_hits_total", "counter", "Rate lookups served from a fresh cache.")
+	m.sample("s_calc_cache_hits_total", "", float64(stats.CacheHits))
+	m.family("s_calc_cache_misses_total", "counter", "Rate lookups that found no fresh cache.")
+	m.sample("s_calc_cache_misses_total", "", float64(stats.CacheMisses))
+
+	providers := make([]string, 0, len(stats.Providers))
+	for name := range stats.Providers {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+
+	m.family("s_calc_provider_requests_total", "counter", "Fetches attempted per provider.")
+	for _, name := range providers {
+		m.sample("s_calc_provider_requests_total", labels("provider", name), float64(stats.Providers[name].Requests))
+	}
+	m.family("s_calc_provider_errors_total", "counter", "Fetches per provider that failed or returned invalid rates.")
+	for _, name := range providers {
+		m.sample("s_calc_provider_errors_total", labels("provider", name), float64(stats.Providers[name].Errors))
+	}
+	m.family("s_calc_provider_fetch_duration_seconds", "summary", "Time taken by provider fetches.")
+	for _, name := range providers {
+		p := stats.Providers[name]
+		m.sample("s_calc_provider_fetch_duration_seconds_sum", labels("provider", name), p.LatencySum.Seconds())
+		m.sample("s_calc_provider_fetch_duration_seconds_count", labels("provider", name), float64(p.Requests))
+	}
+	m.family("s_calc_provider_last_fetch_duration_seconds", "gauge", "Time taken by the most recent fetch per provider.")
+	for _, name := range providers {
+		m.sample("s_calc_provider_last_fetch_duration_seconds", labels("provider", name), stats.Providers[name].LastLatency.Seconds())
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write([]byte(m.String()))
+}
+
+// metricsWriter builds the Prometheus text exposition format.
+type metricsWriter struct {
+	strings.Builder
+}
+
+func (m *metricsWriter) family(name, kind, help string) {
+	fmt.Fprintf(m, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (m *metricsWriter) sample(name, labels string, value float64) {
+	fmt.Fprintf(m, "%s%s %s\n", name, labels, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels formats name/value pairs as a label set, e.g. {base="EUR"}.
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, pairs[i], labelEscaper.Replace(pairs[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
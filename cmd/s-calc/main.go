@@ -3,18 +3,52 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"salary-calc/internal/cli"
+	"salary-calc/internal/config"
 	"salary-calc/internal/converter"
 	"salary-calc/internal/exchangerate"
 	"salary-calc/internal/output"
 )
 
+var commands = map[string]func(args []string) error{
+	"rates":    runRates,
+	"chart":    runChart,
+	"cache":    runCache,
+	"daemon":   runDaemon,
+	"exporter": runExporter,
+	"payroll":  runPayroll,
+	"scenario": runScenario,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	flags, args := cli.ParseFlags()
 
+	opts, err := outputOptions(flags.OutputFlags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	renderer, err := newRenderer(flags, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	var input converter.Input
-	var err error
 
 	if flags.HasInput() {
 		amount, periodStr, currencyStr, ok := flags.GetInput()
@@ -61,7 +95,7 @@ func main() {
 				Currency: currency,
 			}
 		} else {
-			amount, period, currency, err := cli.Interactive()
+			amount, period, currency, err := cli.Interactive(opts.Locale)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
@@ -75,7 +109,7 @@ func main() {
 		}
 	} else {
 		// Interactive mode
-		amount, period, currency, err := cli.Interactive()
+		amount, period, currency, err := cli.Interactive(opts.Locale)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
@@ -88,28 +122,223 @@ func main() {
 		}
 	}
 
-	api, err := exchangerate.NewExchangeRateAPI()
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	api, err := newAPI(cfg, flags.LogFlags)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to initialize exchange rate API: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	rates, rateInfo, err := api.GetRates(string(input.Currency))
+	if flags.Chart != "" {
+		if err := runSalaryChart(api, input, flags, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	api.RequireCurrencies(requiredCurrencies()...)
+	api.SetRevalidator(startBackgroundRefresh)
+
+	rates, rateInfo, err := fetchRates(api, input.Currency, flags)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: failed to fetch exchange rates: %v\n", err)
 		os.Exit(1)
 	}
 
+	overrides, err := loadOverrides(cfg, flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fees, err := loadFees(cfg, flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	conv := converter.NewConverter(rates, string(input.Currency))
+	conv.SetOverrides(overrides)
+	conv.SetFees(fees, rateInfo.Bid)
+	conv.SetMonthlyRates(monthlyRates(rateInfo))
+	for _, currency := range conv.MissingBid() {
+		fmt.Fprintf(os.Stderr, "Warning: %s does not publish a %s bid quote; received amounts use the mid rate\n", rateInfo.Source, currency)
+	}
+	rates = applyOverrides(conv, input.Currency, rates, rateInfo)
+
+	results, err := conv.Convert(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	report := &output.Report{
+		Input:    input,
+		Results:  results,
+		RateInfo: rateInfo,
+		Rates:    rates,
+		Verbose:  flags.Verbose,
+	}
+	if err := applyLayout(report, flags); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if flags.Received || conv.HasFees() {
+		received, err := conv.ConvertReceived(input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		report.Received = received
+		report.Fees = fees
+	}
+
+	out, err := renderer.Render(report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(out)
+}
+
+// monthlyRates returns the rates of each month for -rate-mode=avg-month.
+func monthlyRates(rateInfo *exchangerate.RateInfo) []map[string]float64 {
+	var months []map[string]float64
+	for _, month := range rateInfo.Months {
+		months = append(months, month.Rates)
+	}
+	return months
+}
+
+// requiredCurrencies lists the currencies every fetched rate set must quote.
+func requiredCurrencies() []string {
+	required := make([]string, len(converter.ValidCurrencies))
+	for i, currency := range converter.ValidCurrencies {
+		required[i] = string(currency)
+	}
+	return required
+}
+
+// fetchRates returns the rates selected by -rate-mode, -consensus and
+// -rates-file.
+func fetchRates(api *exchangerate.ExchangeRateAPI, base converter.Currency, flags *cli.Flags) (map[string]float64, *exchangerate.RateInfo, error) {
+	mode, err := exchangerate.ParseRateMode(flags.RateMode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if flags.RatesFile != "" {
+		if mode != exchangerate.RateSpot || flags.Consensus {
+			return nil, nil, fmt.Errorf("-rates-file cannot be used with -rate-mode or -consensus")
+		}
+		return api.GetRatesFrom(exchangerate.NewFileProvider(flags.RatesFile), string(base))
+	}
+
+	if mode != exchangerate.RateSpot {
+		if flags.Consensus {
+			return nil, nil, fmt.Errorf("-consensus applies only to -rate-mode=spot")
+		}
+
+		at := time.Now()
+		if flags.RateDate != "" {
+			if at, err = time.Parse("2006-01-02", flags.RateDate); err != nil {
+				return nil, nil, fmt.Errorf("invalid -rate-date: %s", flags.RateDate)
+			}
+		}
+		return api.GetPeriodRates(string(base), mode, at, flags.Offline)
+	}
+
+	if flags.Consensus {
+		return api.GetConsensusRates(string(base), flags.ConsensusThreshold)
+	}
+	return api.GetRates(string(base))
+}
+
+// loadOverrides merges rate pins from the config file with -rate flags,
+// the flags taking precedence.
+func loadOverrides(cfg *config.Config, flags *cli.Flags) (converter.Overrides, error) {
+	overrides := make(converter.Overrides)
+	for pairStr, rate := range cfg.Rates {
+		pair, err := converter.ParsePair(pairStr)
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("config: rate for %s must be positive", pairStr)
+		}
+		overrides[pair] = rate
+	}
+
+	for _, value := range flags.Rates {
+		pair, rate, err := converter.ParseOverride(value)
+		if err != nil {
+			return nil, err
+		}
+		delete(overrides, converter.Pair{Base: pair.Quote, Quote: pair.Base})
+		overrides[pair] = rate
+	}
+
+	return overrides, nil
+}
+
+// loadFees merges the config file's default fees, the selected fee profile
+// and -fee flags, later sources taking precedence per currency.
+func loadFees(cfg *config.Config, flags *cli.Flags) (converter.Fees, error) {
+	fees := make(converter.Fees)
+
+	sources := []map[string]converter.Fee{cfg.Fees}
+	if flags.FeeProfile != "" {
+		profile, ok := cfg.FeeProfiles[flags.FeeProfile]
+		if !ok {
+			return nil, fmt.Errorf("unknown fee profile: %s", flags.FeeProfile)
+		}
+		sources = append(sources, profile)
+	}
+
+	for _, source := range sources {
+		for currencyStr, fee := range source {
+			currency, err := converter.ValidateCurrency(strings.ToUpper(currencyStr))
+			if err != nil {
+				return nil, fmt.Errorf("config: %w", err)
+			}
+			fees[currency] = fee
+		}
+	}
 
-	results := conv.Convert(input)
+	for _, value := range flags.Fees {
+		currency, fee, err := converter.ParseFee(value)
+		if err != nil {
+			return nil, err
+		}
+		fees[currency] = fee
+	}
 
-	formatter := output.NewTableFormatter(input.Amount, input.Period, input.Currency, rateInfo)
-	table := formatter.Format(results)
-	fmt.Print(table)
+	return fees, nil
+}
+
+// applyOverrides marks pinned rates as manual in rateInfo and returns the
+// rates with the pinned values substituted, for display.
+func applyOverrides(conv *converter.Converter, base converter.Currency, rates map[string]float64, rateInfo *exchangerate.RateInfo) map[string]float64 {
+	display := make(map[string]float64, len(rates))
+	for currency, rate := range rates {
+		display[currency] = rate
+	}
 
-	if flags.Verbose {
-		verbose := output.FormatVerbose(rateInfo, rates)
-		fmt.Print(verbose)
+	for _, currency := range converter.ValidCurrencies {
+		if !conv.IsOverridden(base, currency) {
+			continue
+		}
+		if rate, err := conv.Rate(base, currency); err == nil {
+			display[string(currency)] = rate
+			rateInfo.Kinds[string(currency)] = exchangerate.RateManual
+		}
 	}
+
+	return display
 }
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"salary-calc/internal/cli"
+	"salary-calc/internal/config"
+	"salary-calc/internal/converter"
+	"salary-calc/internal/exchangerate"
+	"salary-calc/internal/locale"
+	"salary-calc/internal/output"
+)
+
+// outputOptions resolves the shared output flags into formatter options.
+func outputOptions(flags cli.OutputFlags) (output.Options, error) {
+	loc, err := locale.Resolve(flags.Locale)
+	if err != nil {
+		return output.Options{}, err
+	}
+
+	colorMode, err := output.ParseColorMode(flags.Color)
+	if err != nil {
+		return output.Options{}, err
+	}
+
+	border, err := output.ParseBorderStyle(flags.Border)
+	if err != nil {
+		return output.Options{}, err
+	}
+	if flags.ASCII {
+		border = output.BorderASCII
+	}
+
+	return output.Options{
+		Locale: loc,
+		Theme:  output.NewTheme(colorMode, border),
+	}, nil
+}
+
+// newLogger returns the stderr logger selected by the logging flags.
+func newLogger(flags cli.LogFlags) (*slog.Logger, error) {
+	if flags.Level == "off" {
+		return slog.New(slog.DiscardHandler), nil
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(flags.Level)); err != nil {
+		return nil, fmt.Errorf("invalid -log-level: %s (valid: debug, info, warn, error, off)", flags.Level)
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	switch flags.Format {
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, handlerOpts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, handlerOpts)), nil
+	default:
+		return nil, fmt.Errorf("invalid -log-format: %s (valid: text, json)", flags.Format)
+	}
+}
+
+// newAPI creates the exchange rate client with the cache backend, provider
+// API keys and additional providers from the config and credentials files,
+// logging as selected by the logging flags.
+func newAPI(cfg *config.Config, logFlags cli.LogFlags) (*exchangerate.ExchangeRateAPI, error) {
+	backend, err := exchangerate.ParseBackend(cfg.Cache.Backend)
+	if err != nil {
+		return nil, err
+	}
+
+	logger, err := newLogger(logFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := config.APIKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	api, err := exchangerate.NewExchangeRateAPIWithBackend(backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize exchange rate API: %w", err)
+	}
+	api.SetAPIKeys(keys)
+	api.SetLogger(logger)
+
+	for _, providerConfig := range cfg.Providers {
+		provider, err := exchangerate.NewHTTPProvider(providerConfig, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid provider in config file: %w", err)
+		}
+		if err := api.AddProvider(provider); err != nil {
+			return nil, err
+		}
+	}
+	return api, nil
+}
+
+// newRenderer returns the renderer selected by -template or -output.
+func newRenderer(flags *cli.Flags, opts output.Options) (output.Renderer, error) {
+	if flags.Template == "" {
+		return output.NewRenderer(flags.Output, opts)
+	}
+	if flags.Output != "table" {
+		return nil, fmt.Errorf("-template and -output cannot be used together")
+	}
+	return output.NewTemplateRenderer(flags.Template, opts)
+}
+
+// applyLayout sets the periods, currencies and orientation the report is
+// shown with from -periods, -currencies, -single and -transpose.
+func applyLayout(report *output.Report, flags *cli.Flags) error {
+	if flags.Single && flags.Currencies != "" {
+		return fmt.Errorf("-single and -currencies cannot be used together")
+	}
+
+	for _, name := range splitList(flags.Periods) {
+		period, err := converter.ValidatePeriod(strings.ToLower(name))
+		if err != nil {
+			return err
+		}
+		report.Periods = append(report.Periods, period)
+	}
+
+	for _, name := range splitList(flags.Currencies) {
+		currency, err := converter.ValidateCurrency(strings.ToUpper(name))
+		if err != nil {
+			return err
+		}
+		report.Currencies = append(report.Currencies, currency)
+	}
+	if flags.Single {
+		report.Currencies = []converter.Currency{report.Input.Currency}
+	}
+
+	report.Transpose = flags.Transpose
+	return nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"salary-calc/internal/cli"
+	"salary-calc/internal/config"
+	"salary-calc/internal/converter"
+	"salary-calc/internal/output"
+	"salary-calc/internal/payroll"
+)
+
+// runPayroll converts every salary in a team file with one set of rates and
+// prints the totals, means and medians.
+func runPayroll(args []string) error {
+	fs := flag.NewFlagSet("payroll", flag.ExitOnError)
+	in := fs.String("in", "", "Payroll CSV file, or - for stdin")
+	currencies := fs.String("currencies", "", "Comma-separated reporting currencies (default: all)")
+	periodName := fs.String("period", "month", "Period for the per-group breakdown: hour, day, month or year")
+	groupBy := fs.String("group-by", "", "Column to break the totals down by, e.g. department")
+	overhead := fs.Float64("overhead", 1, "Fully loaded cost multiplier for rows without an overhead column, e.g. 1.25")
+	format := fs.String("output", "table", "Output format: table, csv or json")
+	ratesFile := fs.String("rates-file", "", "Read rates from a JSON, CSV or ECB XML file instead of a provider")
+	var pins cli.StringList
+	fs.Var(&pins, "rate", "Pin a pair to a fixed rate, e.g. EUR/PLN=4.30 (repeatable)")
+	var outputFlags cli.OutputFlags
+	outputFlags.Register(fs)
+	var logFlags cli.LogFlags
+	logFlags.Register(fs)
+	if _, err := cli.ParseArgs(fs, args); err != nil {
+		return err
+	}
+
+	if *in == "" {
+		return fmt.Errorf("usage: %s payroll -in=team.csv [-group-by=COLUMN] [-currencies=PLN,EUR] [-output=table|csv|json]", os.Args[0])
+	}
+	if *overhead <= 0 {
+		return fmt.Errorf("-overhead must be positive")
+	}
+	if *format != "table" && *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown output format: %s (valid: table, csv, json)", *format)
+	}
+	if *in == "-" && *ratesFile == "-" {
+		return fmt.Errorf("-in and -rates-file cannot both read stdin")
+	}
+
+	period, err := converter.ValidatePeriod(strings.ToLower(*periodName))
+	if err != nil {
+		return err
+	}
+
+	reporting := converter.ValidCurrencies
+	if *currencies != "" {
+		reporting = nil
+		for _, name := range splitList(*currencies) {
+			currency, err := converter.ValidateCurrency(strings.ToUpper(name))
+			if err != nil {
+				return err
+			}
+			reporting = append(reporting, currency)
+		}
+	}
+
+	opts, err := outputOptions(outputFlags)
+	if err != nil {
+		return err
+	}
+
+	rows, err := readPayroll(*in, *overhead)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	api, err := newAPI(cfg, logFlags)
+	if err != nil {
+		return err
+	}
+	api.RequireCurrencies(requiredCurrencies()...)
+
+	// One rate set serves every row, whatever its currency.
+	rateFlags := &cli.Flags{RateMode: "spot", RatesFile: *ratesFile, Rates: pins}
+	base := converter.Currency(api.Base())
+	rates, _, err := fetchRates(api, base, rateFlags)
+	if err != nil {
+		return fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+
+	overrides, err := loadOverrides(cfg, rateFlags)
+	if err != nil {
+		return err
+	}
+
+	conv := converter.NewConverter(rates, string(base))
+	conv.SetOverrides(overrides)
+
+	summary, err := payroll.Summarize(rows, conv, reporting, *groupBy)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "csv":
+		out, err := output.FormatPayrollCSV(summary)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+	case "json":
+		data, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	default:
+		fmt.Print(output.FormatPayroll(summary, period, opts))
+	}
+	return nil
+}
+
+func readPayroll(path string, overhead float64) ([]payroll.Row, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open payroll file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	return payroll.Read(r, overhead)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"salary-calc/internal/cli"
+	"salary-calc/internal/config"
+	"salary-calc/internal/exchangerate"
+	"salary-calc/internal/output"
+)
+
+func runRates(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s rates history|prefetch|export [flags]", os.Args[0])
+	}
+
+	switch args[0] {
+	case "history":
+		return runRatesHistory(args[1:])
+	case "prefetch":
+		return runRatesPrefetch(args[1:])
+	case "export":
+		return runRatesExport(args[1:])
+	default:
+		return fmt.Errorf("unknown rates command: %s", args[0])
+	}
+}
+
+func runRatesHistory(args []string) error {
+	fs := flag.NewFlagSet("rates history", flag.ExitOnError)
+	fromStr := fs.String("from", "", "First day, YYYY-MM-DD (default: 30 days ago)")
+	toStr := fs.String("to", "", "Last day, YYYY-MM-DD (default: today)")
+	backfill := fs.Bool("backfill", false, "Fetch days missing from the local history from a time-series provider")
+	var outputFlags cli.OutputFlags
+	outputFlags.Register(fs)
+	var logFlags cli.LogFlags
+	logFlags.Register(fs)
+
+	positional, err := cli.ParseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: %s rates history EUR/PLN [-from=YYYY-MM-DD] [-to=YYYY-MM-DD] [-backfill]", os.Args[0])
+	}
+
+	base, quote, err := parseRatePair(positional[0])
+	if err != nil {
+		return err
+	}
+
+	opts, err := outputOptions(outputFlags)
+	if err != nil {
+		return err
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if *toStr != "" {
+		if to, err = time.Parse("2006-01-02", *toStr); err != nil {
+			return fmt.Errorf("invalid -to date: %s", *toStr)
+		}
+	}
+	from := to.AddDate(0, 0, -30)
+	if *fromStr != "" {
+		if from, err = time.Parse("2006-01-02", *fromStr); err != nil {
+			return fmt.Errorf("invalid -from date: %s", *fromStr)
+		}
+	}
+	if from.After(to) {
+		return fmt.Errorf("-from must not be after -to")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	api, err := newAPI(cfg, logFlags)
+	if err != nil {
+		return err
+	}
+
+	points, err := api.RateHistory(base, quote, from, to, *backfill)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(output.FormatHistory(base+"/"+quote, points, opts))
+	return nil
+}
+
+// runRatesExport writes the current rates to a file that -rates-file can
+// read on a machine without network access.
+func runRatesExport(args []string) error {
+	fs := flag.NewFlagSet("rates export", flag.ExitOnError)
+	base := fs.String("base", "", "Base currency to quote the rates against (default: S_CALC_BASE)")
+	format := fs.String("format", "", "File format: json, csv or xml (default: from -o, else json)")
+	outPath := fs.String("o", "", "Write to this file instead of stdout")
+	var logFlags cli.LogFlags
+	logFlags.Register(fs)
+	if _, err := cli.ParseArgs(fs, args); err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = exchangerate.FileFormat(*outPath)
+	}
+	if *format == "" {
+		*format = exchangerate.FormatJSON
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	api, err := newAPI(cfg, logFlags)
+	if err != nil {
+		return err
+	}
+
+	if *base == "" {
+		*base = api.Base()
+	}
+	api.RequireCurrencies(requiredCurrencies()...)
+	rates, info, err := api.GetRates(strings.ToUpper(*base))
+	if err != nil {
+		return err
+	}
+
+	date := info.EffectiveDate
+	if date == "" {
+		date = info.Timestamp.UTC().Format("2006-01-02")
+	}
+	file := &exchangerate.RatesFile{
+		Base:   strings.ToUpper(*base),
+		Date:   date,
+		Source: info.Source,
+		Rates:  rates,
+		Bid:    info.Bid,
+		Ask:    info.Ask,
+	}
+
+	var buf bytes.Buffer
+	if err := exchangerate.WriteRatesFile(&buf, file, *format); err != nil {
+		return err
+	}
+
+	if *outPath == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(*outPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write rates file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d %s rates from %s to %s\n", len(rates), file.Base, info.Source, *outPath)
+	return nil
+}
+
+// parseRatePair parses a pair such as "EUR/PLN". Unlike converter.ParsePair
+// it accepts any currency code, as rate sets quote many more currencies.
+func parseRatePair(s string) (string, string, error) {
+	base, quote, ok := strings.Cut(strings.ToUpper(s), "/")
+	if !ok || len(base) != 3 || len(quote) != 3 {
+		return "", "", fmt.Errorf("invalid currency pair: %s (expected e.g. EUR/PLN)", s)
+	}
+	return base, quote, nil
+}
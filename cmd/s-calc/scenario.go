@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"salary-calc/internal/cli"
+	"salary-calc/internal/config"
+	"salary-calc/internal/converter"
+	"salary-calc/internal/output"
+	"salary-calc/internal/payroll"
+	"salary-calc/internal/scenario"
+)
+
+// runScenario shows what a salary, or a whole team, costs in the budget
+// currency when exchange rates move.
+func runScenario(args []string) error {
+	fs := flag.NewFlagSet("scenario", flag.ExitOnError)
+	hour := fs.Float64("h", 0, "Salary per hour")
+	day := fs.Float64("d", 0, "Salary per day")
+	month := fs.Float64("m", 0, "Salary per month")
+	year := fs.Float64("y", 0, "Salary per year")
+	currencyName := fs.String("c", "EUR", "Salary currency")
+	in := fs.String("in", "", "Payroll CSV file, or - for stdin, instead of a single salary")
+	overhead := fs.Float64("overhead", 1, "Fully loaded cost multiplier for payroll rows without an overhead column")
+	shockList := fs.String("shock", "±5%,±10%", "Comma-separated rate moves; ± adds both directions")
+	budgetName := fs.String("budget", "PLN", "Currency the budget is fixed in")
+	periodName := fs.String("period", "", "Period for the sensitivity table: hour, day, month or year (default: the salary's period, or month)")
+	volatility := fs.String("volatility", "", "Add a range from the rate volatility over this lookback, e.g. 1y")
+	horizon := fs.String("horizon", "1m", "How far ahead the volatility range looks, e.g. 30d or 3m")
+	offline := fs.Bool("offline", false, "Use only the local rate history for -volatility")
+	format := fs.String("output", "table", "Output format: table or json")
+	ratesFile := fs.String("rates-file", "", "Read rates from a JSON, CSV or ECB XML file instead of a provider")
+	var outputFlags cli.OutputFlags
+	outputFlags.Register(fs)
+	var logFlags cli.LogFlags
+	logFlags.Register(fs)
+	if _, err := cli.ParseArgs(fs, args); err != nil {
+		return err
+	}
+
+	if *format != "table" && *format != "json" {
+		return fmt.Errorf("unknown output format: %s (valid: table, json)", *format)
+	}
+	if *in == "-" && *ratesFile == "-" {
+		return fmt.Errorf("-in and -rates-file cannot both read stdin")
+	}
+
+	shocks, err := scenario.ParseShocks(*shockList)
+	if err != nil {
+		return err
+	}
+	budget, err := converter.ValidateCurrency(strings.ToUpper(*budgetName))
+	if err != nil {
+		return err
+	}
+
+	salaries, period, err := scenarioSalaries(*in, *overhead, *currencyName, map[converter.Period]float64{
+		converter.PeriodHour:  *hour,
+		converter.PeriodDay:   *day,
+		converter.PeriodMonth: *month,
+		converter.PeriodYear:  *year,
+	})
+	if err != nil {
+		return err
+	}
+	if *periodName != "" {
+		if period, err = converter.ValidatePeriod(strings.ToLower(*periodName)); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now().UTC().Truncate(24 * time.Hour)
+	var from, until time.Time
+	if *volatility != "" {
+		if from, err = cli.ParseLast(*volatility, now); err != nil {
+			return err
+		}
+		if until, err = cli.ParseLast(*horizon, now); err != nil {
+			return err
+		}
+	}
+
+	opts, err := outputOptions(outputFlags)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	api, err := newAPI(cfg, logFlags)
+	if err != nil {
+		return err
+	}
+	api.RequireCurrencies(requiredCurrencies()...)
+
+	base := converter.Currency(api.Base())
+	rates, _, err := fetchRates(api, base, &cli.Flags{RateMode: "spot", RatesFile: *ratesFile})
+	if err != nil {
+		return fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+
+	report, err := scenario.Build(salaries, rates, string(base), budget, period, shocks)
+	if err != nil {
+		return err
+	}
+
+	if *volatility != "" {
+		report.Lookback, report.Horizon = *volatility, *horizon
+		days := now.Sub(until).Hours() / 24
+		for _, exposure := range report.Exposures {
+			points, err := loadSeries(api, string(exposure.Currency), string(budget), from, now, *offline)
+			if err != nil {
+				return err
+			}
+			r, err := scenario.VolatilityRange(exposure, points, days)
+			if err != nil {
+				return err
+			}
+			report.Ranges = append(report.Ranges, r)
+		}
+	}
+
+	if *format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	fmt.Print(output.FormatScenario(report, opts))
+	return nil
+}
+
+// scenarioSalaries returns the salaries to analyse and their default period:
+// either the rows of a payroll file or the one salary given by amounts.
+func scenarioSalaries(in string, overhead float64, currencyName string, amounts map[converter.Period]float64) ([]payroll.Row, converter.Period, error) {
+	usage := fmt.Errorf("usage: %s scenario -m=5000 -c=EUR [-shock=±5%%,±10%%] [-budget=PLN] [-volatility=1y], or -in=team.csv instead of a salary", os.Args[0])
+
+	var input *converter.Input
+	for _, p := range converter.ValidPeriods {
+		if amounts[p] <= 0 {
+			continue
+		}
+		if input != nil {
+			return nil, "", usage
+		}
+		input = &converter.Input{Amount: amounts[p], Period: p}
+	}
+
+	switch {
+	case in != "" && input != nil, in == "" && input == nil:
+		return nil, "", usage
+	case in != "":
+		if overhead <= 0 {
+			return nil, "", fmt.Errorf("-overhead must be positive")
+		}
+		rows, err := readPayroll(in, overhead)
+		return rows, converter.PeriodMonth, err
+	}
+
+	currency, err := converter.ValidateCurrency(strings.ToUpper(currencyName))
+	if err != nil {
+		return nil, "", err
+	}
+	input.Currency = currency
+	return []payroll.Row{{Input: *input, Headcount: 1, Overhead: 1}}, input.Period, nil
+}
@@ -0,0 +1,20 @@
+package cli
+
+import "flag"
+
+// ParseArgs parses fs from args, allowing flags to appear after positional
+// arguments, and returns the positional arguments.
+func ParseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
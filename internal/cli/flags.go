@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+
+	"salary-calc/internal/exchangerate"
 )
 
 type Flags struct {
@@ -14,6 +17,77 @@ type Flags struct {
 	Year     *float64
 	Currency string
 	Verbose  bool
+	Output   string
+	Template string
+
+	Periods    string
+	Currencies string
+	Transpose  bool
+	Single     bool
+
+	OutputFlags
+	LogFlags
+
+	Consensus          bool
+	ConsensusThreshold float64
+	Rates              StringList
+	Fees               StringList
+	FeeProfile         string
+	Received           bool
+
+	RateMode  string
+	RateDate  string
+	RatesFile string
+
+	Chart   string
+	Last    string
+	Offline bool
+}
+
+// OutputFlags are the presentation flags shared by all commands.
+type OutputFlags struct {
+	Locale string
+	Color  string
+	Border string
+	ASCII  bool
+}
+
+// Register adds the output flags to fs.
+func (o *OutputFlags) Register(fs *flag.FlagSet) {
+	fs.StringVar(&o.Locale, "locale", "", "Number format: pl-PL, de-DE, en-GB or en-US (default: from LANG)")
+	fs.StringVar(&o.Color, "color", "auto", "Colorize output: auto, always or never (auto honors NO_COLOR)")
+	fs.StringVar(&o.Border, "border", "unicode", "Table and chart characters: unicode or ascii")
+	fs.BoolVar(&o.ASCII, "ascii", false, "Shorthand for -border=ascii")
+}
+
+// LogFlags select how much of the rate lookup is traced to stderr.
+type LogFlags struct {
+	Level  string
+	Format string
+}
+
+// Register adds the logging flags to fs.
+func (l *LogFlags) Register(fs *flag.FlagSet) {
+	l.RegisterLevel(fs, "warn")
+}
+
+// RegisterLevel adds the logging flags to fs with a default level, e.g.
+// info for long-running commands that report their progress.
+func (l *LogFlags) RegisterLevel(fs *flag.FlagSet, level string) {
+	fs.StringVar(&l.Level, "log-level", level, "Trace rate lookups to stderr: debug, info, warn, error or off")
+	fs.StringVar(&l.Format, "log-format", "text", "Log format: text or json")
+}
+
+// StringList collects the values of a flag that may be repeated.
+type StringList []string
+
+func (l *StringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *StringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
 }
 
 func ParseFlags() (*Flags, []string) {
@@ -25,6 +99,26 @@ func ParseFlags() (*Flags, []string) {
 	flags.Year = flag.Float64("y", 0, "Salary per year")
 	flag.StringVar(&flags.Currency, "c", "EUR", "Currency (PLN, EUR, USD, GBP)")
 	flag.BoolVar(&flags.Verbose, "v", false, "Show detailed rate information")
+	flag.StringVar(&flags.Output, "output", "table", "Output format: table, compact, markdown, html or latex")
+	flag.StringVar(&flags.Template, "template", "", "Render the results with a Go text/template file instead of -output")
+	flag.StringVar(&flags.Periods, "periods", "", "Comma-separated periods to show, e.g. hour,month (default: all)")
+	flag.StringVar(&flags.Currencies, "currencies", "", "Comma-separated currencies to show, e.g. EUR,PLN (default: all)")
+	flag.BoolVar(&flags.Transpose, "transpose", false, "Show currencies as rows and periods as columns")
+	flag.BoolVar(&flags.Single, "single", false, "Show only the input currency")
+	flags.OutputFlags.Register(flag.CommandLine)
+	flags.LogFlags.Register(flag.CommandLine)
+	flag.BoolVar(&flags.Consensus, "consensus", false, "Query all providers and use the median rate")
+	flag.Var(&flags.Rates, "rate", "Pin a pair to a fixed rate, e.g. EUR/PLN=4.30 (repeatable)")
+	flag.Var(&flags.Fees, "fee", "Fee model for a destination currency, e.g. PLN=0.5%+10 or USD=bid (repeatable)")
+	flag.StringVar(&flags.FeeProfile, "fee-profile", "", "Use a named fee profile from the config file")
+	flag.BoolVar(&flags.Received, "received", false, "Show received amounts after fees next to mid-rate amounts")
+	flag.StringVar(&flags.RateMode, "rate-mode", "spot", "Rate to convert at: spot, avg-month, avg-year or period-end (avg-month converts the Year row month by month)")
+	flag.StringVar(&flags.RateDate, "rate-date", "", "Reference date (YYYY-MM-DD) for -rate-mode windows (default: today)")
+	flag.StringVar(&flags.RatesFile, "rates-file", "", "Read rates from a JSON, CSV or ECB XML file, or - for stdin, instead of a provider")
+	flag.StringVar(&flags.Chart, "chart", "", "Chart the salary's value in this currency over time instead of printing the table")
+	flag.StringVar(&flags.Last, "last", "1y", "Lookback window for -chart, e.g. 90d, 6m, 1y")
+	flag.BoolVar(&flags.Offline, "offline", false, "Use only locally stored rate history for -chart and -rate-mode")
+	flag.Float64Var(&flags.ConsensusThreshold, "consensus-threshold", exchangerate.DefaultConsensusThreshold, "Flag providers deviating from the median by more than this percentage")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n\n", os.Args[0])
@@ -33,6 +127,9 @@ func ParseFlags() (*Flags, []string) {
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  %s -h=20 EUR\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -m=5000 -c=USD\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -m=5000 -c=EUR -rate EUR/PLN=4.30\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -m=5000 -c=EUR -output=markdown > salary.md\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -h=20 -c=EUR -currencies=PLN -periods=hour,month -output=compact\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s  (interactive mode)\n", os.Args[0])
 	}
 
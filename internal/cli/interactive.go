@@ -7,10 +7,11 @@ import (
 	"strings"
 
 	"salary-calc/internal/converter"
+	"salary-calc/internal/locale"
 )
 
-// Interactive prompts user for input
-func Interactive() (float64, converter.Period, converter.Currency, error) {
+// Interactive prompts user for input, reading amounts in the given locale
+func Interactive(loc locale.Locale) (float64, converter.Period, converter.Currency, error) {
 	reader := bufio.NewReader(os.Stdin)
 
 	period, err := promptPeriod(reader)
@@ -18,7 +19,7 @@ func Interactive() (float64, converter.Period, converter.Currency, error) {
 		return 0, "", "", err
 	}
 
-	amount, err := promptAmount(reader)
+	amount, err := promptAmount(reader, loc)
 	if err != nil {
 		return 0, "", "", err
 	}
@@ -53,9 +54,9 @@ func promptPeriod(reader *bufio.Reader) (converter.Period, error) {
 	}
 }
 
-func promptAmount(reader *bufio.Reader) (float64, error) {
+func promptAmount(reader *bufio.Reader, loc locale.Locale) (float64, error) {
 	for {
-		fmt.Print("Enter amount: ")
+		fmt.Printf("Enter amount (e.g. %s): ", loc.FormatNumber(5000, 2))
 		input, err := reader.ReadString('\n')
 		if err != nil {
 			return 0, err
@@ -66,9 +67,9 @@ func promptAmount(reader *bufio.Reader) (float64, error) {
 			continue
 		}
 
-		amount, err := parseFloat(input)
+		amount, err := loc.ParseNumber(input)
 		if err != nil {
-			fmt.Printf("Error: invalid number: %v\n", err)
+			fmt.Printf("Error: %v\n", err)
 			continue
 		}
 
@@ -102,10 +103,3 @@ func promptCurrency(reader *bufio.Reader) (converter.Currency, error) {
 		fmt.Printf("Error: %v\n", err)
 	}
 }
-
-func parseFloat(s string) (float64, error) {
-	s = strings.TrimSpace(s)
-	var result float64
-	_, err := fmt.Sscanf(s, "%f", &result)
-	return result, err
-}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ParseLast parses a lookback such as "90d", "12w", "6m" or "1y" and returns
+// the date that far before now.
+func ParseLast(s string, now time.Time) (time.Time, error) {
+	if len(s) < 2 {
+		return time.Time{}, fmt.Errorf("invalid lookback: %q (expected e.g. 90d, 12w, 6m, 1y)", s)
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return time.Time{}, fmt.Errorf("invalid lookback: %q (expected e.g. 90d, 12w, 6m, 1y)", s)
+	}
+
+	switch s[len(s)-1] {
+	case 'd':
+		return now.AddDate(0, 0, -n), nil
+	case 'w':
+		return now.AddDate(0, 0, -7*n), nil
+	case 'm':
+		return now.AddDate(0, -n, 0), nil
+	case 'y':
+		return now.AddDate(-n, 0, 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid lookback: %q (expected e.g. 90d, 12w, 6m, 1y)", s)
+	}
+}
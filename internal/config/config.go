@@ -0,0 +1,79 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"salary-calc/internal/converter"
+	"salary-calc/internal/exchangerate"
+)
+
+// Config is the optional JSON configuration file.
+type Config struct {
+	// Rates pins pairs such as "EUR/PLN" to a fixed rate.
+	Rates map[string]float64 `json:"rates"`
+	// Fees maps a destination currency such as "PLN" to its fee model.
+	Fees map[string]converter.Fee `json:"fees"`
+	// FeeProfiles holds named fee sets, e.g. "bank" or "wise", selected with -fee-profile.
+	FeeProfiles map[string]map[string]converter.Fee `json:"fee_profiles"`
+	// Prefetch configures which rate caches "s-calc daemon" and
+	// "s-calc rates prefetch" keep warm.
+	Prefetch Prefetch `json:"prefetch"`
+	// Cache selects where rates and history are stored.
+	Cache Cache `json:"cache"`
+	// APIKeys maps a provider name such as "exchangerate.host" to its API key.
+	// Prefer the credentials file, which must not be readable by others.
+	APIKeys map[string]string `json:"api_keys"`
+	// Providers defines additional JSON rate sources, tried in order before
+	// the built-in providers.
+	Providers []exchangerate.HTTPProviderConfig `json:"providers"`
+}
+
+// Cache configures the rate cache. Backend is "file" (default), "kv" or "memory".
+type Cache struct {
+	Backend string `json:"backend"`
+}
+
+// Prefetch lists the canonical bases to keep cached and how often to check them.
+type Prefetch struct {
+	Bases    []string `json:"bases"`
+	Interval string   `json:"interval"`
+}
+
+// Load reads the configuration file. A missing file yields an empty Config.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// Path returns the location of the configuration file.
+func Path() (string, error) {
+	if customPath := os.Getenv("S_CALC_CONFIG"); customPath != "" {
+		return customPath, nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "s-calc", "config.json"), nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// APIKeys returns the provider API keys from the config file merged with the
+// credentials file, whose keys take precedence. A missing credentials file
+// is not an error; one readable by group or others is, and so is a config
+// file holding api_keys.
+func APIKeys(cfg *Config) (map[string]string, error) {
+	keys := make(map[string]string, len(cfg.APIKeys))
+	for provider, key := range cfg.APIKeys {
+		keys[provider] = key
+	}
+
+	if len(cfg.APIKeys) > 0 {
+		path, err := Path()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPrivate("config", path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	path, err := CredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPrivate("credentials", path); err != nil {
+		if os.IsNotExist(err) {
+			return keys, nil
+		}
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var credentials map[string]string
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file %s: %w", path, err)
+	}
+	for provider, key := range credentials {
+		keys[provider] = key
+	}
+
+	return keys, nil
+}
+
+// checkPrivate returns an error if the file at path, which holds secrets, is
+// readable by group or others. A missing file yields an os.IsNotExist error.
+func checkPrivate(kind, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return err
+		}
+		return fmt.Errorf("failed to read %s file: %w", kind, err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("%s file %s has permissions %04o, run: chmod 600 %s", kind, path, info.Mode().Perm(), path)
+	}
+	return nil
+}
+
+// CredentialsPath returns the location of the credentials file.
+func CredentialsPath() (string, error) {
+	if customPath := os.Getenv("S_CALC_CREDENTIALS"); customPath != "" {
+		return customPath, nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "s-calc", "credentials.json"), nil
+}
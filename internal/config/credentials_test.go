@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestAPIKeysPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not checked on Windows")
+	}
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	credentialsPath := filepath.Join(dir, "credentials.json")
+	t.Setenv("S_CALC_CONFIG", configPath)
+	t.Setenv("S_CALC_CREDENTIALS", credentialsPath)
+
+	write := func(path, content string, perm os.FileMode) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), perm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A readable config file without keys is fine.
+	write(configPath, `{}`, 0644)
+	if _, err := APIKeys(&Config{}); err != nil {
+		t.Errorf("config without api_keys: %v", err)
+	}
+
+	cfg := &Config{APIKeys: map[string]string{"exchangerate.host": "from-config"}}
+	if _, err := APIKeys(cfg); err == nil || !strings.Contains(err.Error(), "config file") {
+		t.Errorf("readable config with api_keys: err = %v, want a permissions error", err)
+	}
+
+	write(configPath, `{}`, 0600)
+	write(credentialsPath, `{"exchangerate.host":"from-credentials"}`, 0640)
+	if _, err := APIKeys(cfg); err == nil || !strings.Contains(err.Error(), "credentials file") {
+		t.Errorf("readable credentials: err = %v, want a permissions error", err)
+	}
+
+	write(credentialsPath, `{"exchangerate.host":"from-credentials"}`, 0600)
+	keys, err := APIKeys(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys["exchangerate.host"] != "from-credentials" {
+		t.Errorf("key = %q, want the credentials file to take precedence", keys["exchangerate.host"])
+	}
+}
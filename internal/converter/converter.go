@@ -39,6 +39,10 @@ type Converter struct {
 	daysPerMonth int
 	rates        map[string]float64
 	baseCurrency string
+	overrides    Overrides
+	fees         Fees
+	bid          map[string]float64
+	months       []map[string]float64
 }
 
 func NewConverter(rates map[string]float64, baseCurrency string) *Converter {
@@ -64,21 +68,77 @@ func NewConverter(rates map[string]float64, baseCurrency string) *Converter {
 	}
 }
 
-func (c *Converter) Convert(input Input) map[Period]map[Currency]float64 {
+// SetOverrides pins pairs to fixed rates, taking precedence over provider rates.
+func (c *Converter) SetOverrides(overrides Overrides) {
+	c.overrides = overrides
+}
+
+// SetMonthlyRates makes the Year amounts the sum of one Month per rate set in
+// months, each converted at its own rates, instead of twelve Months at the
+// converter's rates. The rates are quoted against the converter's base.
+func (c *Converter) SetMonthlyRates(months []map[string]float64) {
+	c.months = months
+}
+
+// yearByMonth sums the Month amounts that convert returns for each monthly
+// rate set.
+func (c *Converter) yearByMonth(convert func(*Converter, Input) (map[Period]map[Currency]float64, error), input Input) (map[Currency]float64, error) {
+	year := make(map[Currency]float64)
+	for _, rates := range c.months {
+		month := *c
+		month.rates, month.months = rates, nil
+		results, err := convert(&month, input)
+		if err != nil {
+			return nil, err
+		}
+		for currency, amount := range results[PeriodMonth] {
+			year[currency] += amount
+		}
+	}
+	return year, nil
+}
+
+// IsOverridden reports whether converting from one currency to another uses a pinned rate.
+func (c *Converter) IsOverridden(from, to Currency) bool {
+	if from == to {
+		return false
+	}
+	_, ok, _ := c.overrideRate(string(from), string(to))
+	return ok
+}
+
+// Rate returns the rate used to convert from one currency to another.
+func (c *Converter) Rate(from, to Currency) (float64, error) {
+	return c.getRate(string(from), string(to))
+}
+
+func (c *Converter) Convert(input Input) (map[Period]map[Currency]float64, error) {
 	baseHourly := c.toHourly(input.Amount, input.Period)
 
 	result := make(map[Period]map[Currency]float64)
 	for _, period := range ValidPeriods {
 		result[period] = make(map[Currency]float64)
 		for _, currency := range ValidCurrencies {
+			rate, err := c.getRate(string(input.Currency), string(currency))
+			if err != nil {
+				return nil, err
+			}
 			// Convert currency first
-			amountInCurrency := baseHourly * c.getRate(string(input.Currency), string(currency))
+			amountInCurrency := baseHourly * rate
 			// Then convert period
 			result[period][currency] = c.fromHourly(amountInCurrency, period)
 		}
 	}
 
-	return result
+	if len(c.months) > 0 {
+		year, err := c.yearByMonth((*Converter).Convert, input)
+		if err != nil {
+			return nil, err
+		}
+		result[PeriodYear] = year
+	}
+
+	return result, nil
 }
 
 func (c *Converter) toHourly(amount float64, period Period) float64 {
@@ -111,40 +171,90 @@ func (c *Converter) fromHourly(amount float64, period Period) float64 {
 	}
 }
 
-func (c *Converter) getRate(from, to string) float64 {
+func (c *Converter) getRate(from, to string) (float64, error) {
 	if from == to {
-		return 1.0
+		return 1.0, nil
+	}
+
+	if rate, ok, err := c.overrideRate(from, to); ok || err != nil {
+		return rate, err
+	}
+
+	return c.marketRate(from, to)
+}
+
+// overrideRate converts through a pinned pair when one applies. A pin on
+// EUR/PLN fixes the value of PLN, so USD->PLN becomes USD->EUR at market
+// times the pinned EUR->PLN and cross rates stay consistent with the pin.
+func (c *Converter) overrideRate(from, to string) (float64, bool, error) {
+	if rate, ok := c.overrides[Pair{Base: Currency(from), Quote: Currency(to)}]; ok {
+		return rate, true, nil
+	}
+	if rate, ok := c.overrides[Pair{Base: Currency(to), Quote: Currency(from)}]; ok {
+		return 1.0 / rate, true, nil
+	}
+
+	for _, pair := range c.overrides.pairs() {
+		rate := c.overrides[pair]
+		base, quote := string(pair.Base), string(pair.Quote)
+
+		var leg float64
+		var err error
+		switch {
+		case quote == to:
+			leg, err = c.marketRate(from, base)
+		case quote == from:
+			leg, err = c.marketRate(base, to)
+			rate = 1.0 / rate
+		default:
+			continue
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		return leg * rate, true, nil
+	}
+
+	return 0, false, nil
+}
+
+func (c *Converter) marketRate(from, to string) (float64, error) {
+	if from == to {
+		return 1.0, nil
 	}
 
 	if c.rates == nil {
-		return 1.0
+		return 0, fmt.Errorf("no exchange rates available")
 	}
 
 	var fromRate float64 = 1.0
 	if from != c.baseCurrency {
-		if rate, ok := c.rates[from]; ok {
-			fromRate = rate
-		} else {
-			return 1.0 // Unknown currency
+		rate, ok := c.rates[from]
+		if !ok || rate <= 0 {
+			return 0, fmt.Errorf("no exchange rate for %s", from)
 		}
+		fromRate = rate
 	}
 
 	var toRate float64 = 1.0
 	if to != c.baseCurrency {
-		if rate, ok := c.rates[to]; ok {
-			toRate = rate
-		} else {
-			return 1.0 // Unknown currency
+		rate, ok := c.rates[to]
+		if !ok || rate <= 0 {
+			return 0, fmt.Errorf("no exchange rate for %s", to)
 		}
+		toRate = rate
 	}
 
 	// Convert: fromCurrency -> baseCurrency -> toCurrency
 	// If fromRate = 4.25 (PLN), toRate = 1.10 (USD), base = EUR
 	// To convert 100 PLN to USD: 100 / 4.25 * 1.10 = 100 * (1.10 / 4.25)
-	return toRate / fromRate
+	return toRate / fromRate, nil
 }
 
 func ValidateCurrency(currency string) (Currency, error) {
+	if currency == "" {
+		return "", fmt.Errorf("missing currency (supported: PLN, EUR, USD, GBP)")
+	}
 	for _, c := range ValidCurrencies {
 		if string(c) == currency || string(c) == fmt.Sprintf("%s%s", string(currency[0]-32), currency[1:]) {
 			return c, nil
@@ -154,6 +264,9 @@ func ValidateCurrency(currency string) (Currency, error) {
 }
 
 func ValidatePeriod(period string) (Period, error) {
+	if period == "" {
+		return "", fmt.Errorf("missing period (supported: Hour, Day, Month, Year)")
+	}
 	for _, p := range ValidPeriods {
 		if string(p) == period || string(p) == fmt.Sprintf("%s%s", string(period[0]-32), period[1:]) {
 			return p, nil
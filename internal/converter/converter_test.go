@@ -0,0 +1,75 @@
+package converter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValidateCurrency(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Currency
+		wantErr bool
+	}{
+		{"PLN", CurrencyPLN, false},
+		{"eur", "", true},
+		{"CHF", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ValidateCurrency(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ValidateCurrency(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestValidatePeriod(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Period
+		wantErr bool
+	}{
+		{"Month", PeriodMonth, false},
+		{"month", PeriodMonth, false},
+		{"week", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ValidatePeriod(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ValidatePeriod(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestConvertMonthlyRates(t *testing.T) {
+	conv := NewConverter(map[string]float64{"EUR": 1, "PLN": 4.4, "USD": 1.1, "GBP": 0.85}, "EUR")
+	conv.SetMonthlyRates([]map[string]float64{
+		{"EUR": 1, "PLN": 4.0, "USD": 1.0, "GBP": 0.8},
+		{"EUR": 1, "PLN": 4.4, "USD": 1.1, "GBP": 0.85},
+	})
+	conv.SetFees(Fees{CurrencyPLN: {Fixed: 10}}, nil)
+
+	input := Input{Amount: 1000, Period: PeriodMonth, Currency: CurrencyEUR}
+	results, err := conv.Convert(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := results[PeriodMonth][CurrencyPLN]; math.Abs(got-4400) > 1e-9 {
+		t.Errorf("Month PLN = %v, want 4400", got)
+	}
+	if got := results[PeriodYear][CurrencyPLN]; math.Abs(got-8400) > 1e-9 {
+		t.Errorf("Year PLN = %v, want 4000 + 4400", got)
+	}
+
+	received, err := conv.ConvertReceived(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := received[PeriodYear][CurrencyPLN]; math.Abs(got-8380) > 1e-9 {
+		t.Errorf("received Year PLN = %v, want 3990 + 4390", got)
+	}
+}
@@ -0,0 +1,153 @@
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Fee describes what a transfer into a currency costs on top of the mid rate.
+// Fixed is charged in the destination currency once per monthly transfer.
+type Fee struct {
+	SpreadPercent float64 `json:"spread_pct"`
+	Fixed         float64 `json:"fixed"`
+	UseBidAsk     bool    `json:"bid_ask"`
+}
+
+// Fees maps a destination currency to its fee model.
+type Fees map[Currency]Fee
+
+func (f Fee) String() string {
+	var parts []string
+	if f.UseBidAsk {
+		parts = append(parts, "bid/ask")
+	}
+	if f.SpreadPercent != 0 {
+		parts = append(parts, strconv.FormatFloat(f.SpreadPercent, 'f', -1, 64)+"% spread")
+	}
+	if f.Fixed != 0 {
+		parts = append(parts, strconv.FormatFloat(f.Fixed, 'f', -1, 64)+" per transfer")
+	}
+	if len(parts) == 0 {
+		return "no fee"
+	}
+	return strings.Join(parts, " + ")
+}
+
+// ParseFee parses a fee such as "PLN=0.5%+10" or "USD=bid+5".
+func ParseFee(s string) (Currency, Fee, error) {
+	currencyStr, model, ok := strings.Cut(s, "=")
+	if !ok || model == "" {
+		return "", Fee{}, fmt.Errorf("invalid fee: %s (expected e.g. PLN=0.5%%+10)", s)
+	}
+
+	currency, err := ValidateCurrency(strings.ToUpper(strings.TrimSpace(currencyStr)))
+	if err != nil {
+		return "", Fee{}, err
+	}
+
+	var fee Fee
+	for _, part := range strings.Split(model, "+") {
+		part = strings.TrimSpace(part)
+		switch {
+		case strings.EqualFold(part, "bid"), strings.EqualFold(part, "bidask"):
+			fee.UseBidAsk = true
+		case strings.HasSuffix(part, "%"):
+			spread, err := strconv.ParseFloat(strings.TrimSuffix(part, "%"), 64)
+			if err != nil || spread < 0 || spread >= 100 {
+				return "", Fee{}, fmt.Errorf("invalid fee spread: %s", part)
+			}
+			fee.SpreadPercent = spread
+		default:
+			fixed, err := strconv.ParseFloat(part, 64)
+			if err != nil || fixed < 0 {
+				return "", Fee{}, fmt.Errorf("invalid fixed fee: %s", part)
+			}
+			fee.Fixed = fixed
+		}
+	}
+
+	return currency, fee, nil
+}
+
+// SetFees sets the fee models used by ConvertReceived. bid holds the bid
+// quotes against the converter's base currency, if the provider publishes them.
+func (c *Converter) SetFees(fees Fees, bid map[string]float64) {
+	c.fees = fees
+	c.bid = bid
+}
+
+// HasFees reports whether any fee model is configured.
+func (c *Converter) HasFees() bool {
+	return len(c.fees) > 0
+}
+
+// ConvertReceived is like Convert but returns the amounts that arrive after
+// spreads and per-transfer fees. Amounts in the input currency are unchanged.
+func (c *Converter) ConvertReceived(input Input) (map[Period]map[Currency]float64, error) {
+	baseHourly := c.toHourly(input.Amount, input.Period)
+
+	result := make(map[Period]map[Currency]float64)
+	for _, period := range ValidPeriods {
+		result[period] = make(map[Currency]float64)
+	}
+
+	for _, currency := range ValidCurrencies {
+		rate, err := c.getReceivedRate(string(input.Currency), string(currency))
+		if err != nil {
+			return nil, err
+		}
+
+		monthly := c.fromHourly(baseHourly*rate, PeriodMonth)
+		if currency != input.Currency {
+			monthly -= c.fees[currency].Fixed
+		}
+		hourly := c.toHourly(monthly, PeriodMonth)
+
+		for _, period := range ValidPeriods {
+			result[period][currency] = c.fromHourly(hourly, period)
+		}
+	}
+
+	if len(c.months) > 0 {
+		year, err := c.yearByMonth((*Converter).ConvertReceived, input)
+		if err != nil {
+			return nil, err
+		}
+		result[PeriodYear] = year
+	}
+
+	return result, nil
+}
+
+// MissingBid returns the currencies whose fee model uses the bid quote but
+// for which no bid was published, so ConvertReceived uses the mid rate.
+func (c *Converter) MissingBid() []Currency {
+	var missing []Currency
+	for _, currency := range ValidCurrencies {
+		fee := c.fees[currency]
+		if !fee.UseBidAsk || currency == Currency(c.baseCurrency) || c.IsOverridden(Currency(c.baseCurrency), currency) {
+			continue
+		}
+		if bid, ok := c.bid[string(currency)]; !ok || bid <= 0 {
+			missing = append(missing, currency)
+		}
+	}
+	return missing
+}
+
+func (c *Converter) getReceivedRate(from, to string) (float64, error) {
+	rate, err := c.getRate(from, to)
+	if err != nil || from == to {
+		return rate, err
+	}
+
+	fee := c.fees[Currency(to)]
+	if fee.UseBidAsk && from == c.baseCurrency && !c.IsOverridden(Currency(from), Currency(to)) {
+		if bid, ok := c.bid[to]; ok && bid > 0 {
+			rate = bid
+		}
+	}
+
+	return rate * (1 - fee.SpreadPercent/100), nil
+}
@@ -0,0 +1,75 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Pair is a currency pair quoted as units of Quote per one unit of Base.
+type Pair struct {
+	Base  Currency
+	Quote Currency
+}
+
+func (p Pair) String() string {
+	return string(p.Base) + "/" + string(p.Quote)
+}
+
+// Overrides pins currency pairs to fixed rates, e.g. a contract rate.
+type Overrides map[Pair]float64
+
+// ParsePair parses a pair such as "EUR/PLN".
+func ParsePair(s string) (Pair, error) {
+	base, quote, ok := strings.Cut(strings.ToUpper(strings.TrimSpace(s)), "/")
+	if !ok || base == "" || quote == "" {
+		return Pair{}, fmt.Errorf("invalid currency pair: %s (expected e.g. EUR/PLN)", s)
+	}
+
+	baseCurrency, err := ValidateCurrency(base)
+	if err != nil {
+		return Pair{}, err
+	}
+	quoteCurrency, err := ValidateCurrency(quote)
+	if err != nil {
+		return Pair{}, err
+	}
+	if baseCurrency == quoteCurrency {
+		return Pair{}, fmt.Errorf("invalid currency pair: %s (currencies must differ)", s)
+	}
+
+	return Pair{Base: baseCurrency, Quote: quoteCurrency}, nil
+}
+
+// ParseOverride parses an override such as "EUR/PLN=4.30".
+func ParseOverride(s string) (Pair, float64, error) {
+	pairStr, rateStr, ok := strings.Cut(s, "=")
+	if !ok {
+		return Pair{}, 0, fmt.Errorf("invalid rate override: %s (expected e.g. EUR/PLN=4.30)", s)
+	}
+
+	pair, err := ParsePair(pairStr)
+	if err != nil {
+		return Pair{}, 0, err
+	}
+
+	rate, err := strconv.ParseFloat(strings.TrimSpace(rateStr), 64)
+	if err != nil || rate <= 0 {
+		return Pair{}, 0, fmt.Errorf("invalid rate override: %s (rate must be a positive number)", s)
+	}
+
+	return pair, rate, nil
+}
+
+// pairs returns the overridden pairs in a stable order.
+func (o Overrides) pairs() []Pair {
+	pairs := make([]Pair, 0, len(o))
+	for pair := range o {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].String() < pairs[j].String()
+	})
+	return pairs
+}
@@ -4,23 +4,57 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 )
 
 type ExchangeRateAPI struct {
-	cache *Cache
+	cache          *Cache
+	history        *History
+	base           string
+	providers      []Provider
+	required       []string
+	maxDailyChange float64
+	maxStale       time.Duration
+	revalidate     func() error
+	apiKeys        map[string]string
+	metrics        metrics
+	logger         *slog.Logger
 }
 
 func NewExchangeRateAPI() (*ExchangeRateAPI, error) {
-	cache, err := NewCache()
+	return NewExchangeRateAPIWithBackend("")
+}
+
+// NewExchangeRateAPIWithBackend stores rates and history with backend,
+// unless S_CALC_CACHE_BACKEND overrides it.
+func NewExchangeRateAPIWithBackend(backend Backend) (*ExchangeRateAPI, error) {
+	cache, err := NewCache(backend)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ExchangeRateAPI{
-		cache: cache,
-	}, nil
+	api := &ExchangeRateAPI{
+		cache:          cache,
+		history:        newCacheHistory(cache),
+		base:           getCanonicalBase(),
+		maxDailyChange: getMaxDailyChange(),
+		maxStale:       getMaxStale(),
+		logger:         slog.New(slog.DiscardHandler),
+	}
+	api.providers = []Provider{
+		&provider{name: primaryName, fetch: api.fetchFromPrimary},
+		&seriesProvider{
+			provider: provider{name: fallbackName, fetch: api.fetchFromFallback},
+			series:   api.fetchSeriesFromFallback,
+		},
+	}
+
+	return api, nil
 }
 
 type RateResponse struct {
@@ -29,96 +63,411 @@ type RateResponse struct {
 	Date  string             `json:"date"`
 }
 
+// AddProvider adds p to the providers, ahead of the built-in ones and after
+// providers added before it.
+func (api *ExchangeRateAPI) AddProvider(p Provider) error {
+	for _, existing := range api.providers {
+		if existing.Name() == p.Name() {
+			return fmt.Errorf("duplicate provider name: %s", p.Name())
+		}
+	}
+
+	added := 0
+	for _, existing := range api.providers {
+		if existing.Name() == primaryName || existing.Name() == fallbackName {
+			break
+		}
+		added++
+	}
+	api.providers = slices.Insert(api.providers, added, p)
+	return nil
+}
+
+// SetLogger makes the API trace cache lookups, provider attempts and cache
+// writes to logger. By default nothing is logged.
+func (api *ExchangeRateAPI) SetLogger(logger *slog.Logger) {
+	api.logger = logger
+}
+
+// Logger returns the logger set with SetLogger, for callers that report
+// their own progress alongside the API's.
+func (api *ExchangeRateAPI) Logger() *slog.Logger {
+	return api.logger
+}
+
+// RequireCurrencies makes provider responses that lack any of currencies invalid.
+func (api *ExchangeRateAPI) RequireCurrencies(currencies ...string) {
+	api.required = currencies
+}
+
 func (api *ExchangeRateAPI) GetRates(baseCurrency string) (map[string]float64, *RateInfo, error) {
-	if cached, err := api.cache.Get(baseCurrency); err == nil && cached != nil {
-		return cached.Rates, &RateInfo{
-			Source:    cached.Source,
-			Timestamp: cached.Timestamp,
-			ExpiresAt: cached.ExpiresAt,
-		}, nil
+	table, info, err := api.getTable()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rebase(table, info, baseCurrency)
+}
+
+func rebase(table *RateTable, info *RateInfo, baseCurrency string) (map[string]float64, *RateInfo, error) {
+	rates, kinds, err := table.Rebase(baseCurrency)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Rates the provider itself triangulated stay derived.
+	for currency, kind := range info.Kinds {
+		if kind == RateDerived && currency != baseCurrency {
+			kinds[currency] = RateDerived
+		}
+	}
+
+	info.Base = table.Base
+	info.Kinds = kinds
+	info.Mode = RateSpot
+	info.Bid, info.Ask = table.RebaseBidAsk(baseCurrency)
+	return rates, info, nil
+}
+
+// getTable returns the canonical rate table, fetching it only when the cache
+// has no fresh copy.
+func (api *ExchangeRateAPI) getTable() (*RateTable, *RateInfo, error) {
+	base := api.base
+
+	cached, err := api.cache.Get(base)
+	if err != nil {
+		api.logger.Warn("cache read failed", "base", base, "error", err)
+	}
+	if cached != nil {
+		api.metrics.cacheLookup(true)
+		api.logger.Debug("cache hit", "base", base, "source", cached.Source, "expires_at", cached.ExpiresAt)
+		table, info := fromCache(cached)
+		return table, info, nil
+	}
+	api.metrics.cacheLookup(false)
+
+	previous, _ := api.cache.Load(base)
+	if previous != nil {
+		api.logger.Debug("cache expired", "base", base, "source", previous.Source, "expired_at", previous.ExpiresAt)
+	} else {
+		api.logger.Debug("cache miss", "base", base)
 	}
 
-	rates, info, err := api.fetchFromPrimary(baseCurrency)
-	if err == nil {
-		_ = api.cache.Set(baseCurrency, rates, info.Source)
-		return rates, info, nil
+	if api.canRevalidate(previous) {
+		api.logger.Info("serving expired rates while refreshing in background", "base", base, "expired_at", previous.ExpiresAt)
+		table, info := fromCache(previous)
+		info.Revalidating = true
+		api.startRevalidate()
+		return table, info, nil
 	}
 
-	rates, info, err = api.fetchFromFallback(baseCurrency)
-	if err == nil {
-		_ = api.cache.Set(baseCurrency, rates, info.Source)
-		return rates, info, nil
+	table, info, fetchErr := api.fetchTable(api.providers, previous)
+	if fetchErr == nil {
+		return table, info, nil
 	}
 
-	if cached, err := api.cache.Get(baseCurrency); err == nil && cached != nil {
-		return cached.Rates, &RateInfo{
-			Source:    cached.Source + " (expired)",
-			Timestamp: cached.Timestamp,
-			ExpiresAt: cached.ExpiresAt,
-		}, nil
+	if previous != nil {
+		api.logger.Info("all providers failed, serving expired rates", "base", base, "expired_at", previous.ExpiresAt, "error", fetchErr)
+		table, info := fromCache(previous)
+		info.Source += " (expired)"
+		info.Stale = true
+		return table, info, nil
 	}
 
-	return nil, nil, fmt.Errorf("failed to fetch exchange rates: %w", err)
+	return nil, nil, fmt.Errorf("failed to fetch exchange rates: %w", fetchErr)
+}
+
+// fetchTable asks providers in order for the canonical rates and stores the
+// first valid answer.
+func (api *ExchangeRateAPI) fetchTable(providers []Provider, previous *CacheData) (*RateTable, *RateInfo, error) {
+	base := api.base
+
+	var fetchErr error
+	for _, p := range providers {
+		rates, info, err := api.fetchFrom(p, base, previous)
+		if err != nil {
+			fetchErr = fmt.Errorf("%s: %w", p.Name(), err)
+			continue
+		}
+		// Rates from a dated file keep their date, so that old rates are not
+		// cached as fresh.
+		if info.Timestamp.IsZero() {
+			info.Timestamp = time.Now()
+		}
+		api.store(&CacheData{
+			Base:          base,
+			Rates:         rates,
+			Source:        info.Source,
+			Bid:           info.Bid,
+			Ask:           info.Ask,
+			EffectiveDate: info.EffectiveDate,
+			PayloadDigest: info.PayloadDigest,
+			Quota:         info.Quota,
+		}, info.Timestamp)
+		info.ExpiresAt = info.Timestamp.Add(api.cache.ttl)
+		info.Stale = time.Now().After(info.ExpiresAt)
+		return &RateTable{Base: base, Rates: rates, Bid: info.Bid, Ask: info.Ask}, info, nil
+	}
+	return nil, nil, fetchErr
+}
+
+// store caches data as of timestamp and records it in the rate history
+// under its effective date, or the day of timestamp. Failures are logged, as
+// the rates are still usable for this run.
+func (api *ExchangeRateAPI) store(data *CacheData, timestamp time.Time) {
+	if err := api.cache.PutAt(data, timestamp); err != nil {
+		api.logger.Warn("cache write failed", "base", data.Base, "error", err)
+	} else {
+		api.logger.Debug("cache write", "base", data.Base, "source", data.Source, "expires_at", data.ExpiresAt)
+	}
+
+	day := timestamp.UTC().Format(dateLayout)
+	if _, err := time.Parse(dateLayout, data.EffectiveDate); err == nil {
+		day = data.EffectiveDate
+	}
+	api.record(day, data.Base, data.Rates, data.Source)
+}
+
+// Refresh fetches the canonical rates and replaces the cached copy even if it
+// has not expired. When providerName is set, only that provider is asked.
+func (api *ExchangeRateAPI) Refresh(providerName string) (*RateInfo, error) {
+	providers := api.providers
+	if providerName != "" {
+		providers = nil
+		var names []string
+		for _, p := range api.providers {
+			if p.Name() == providerName {
+				providers = append(providers, p)
+			}
+			names = append(names, p.Name())
+		}
+		if providers == nil {
+			return nil, fmt.Errorf("unknown provider: %s (available: %s)", providerName, strings.Join(names, ", "))
+		}
+	}
+
+	return api.refresh(providers)
+}
+
+// RefreshFrom replaces the cached rates with those from p, which need not be
+// one of the configured providers, e.g. a rates file.
+func (api *ExchangeRateAPI) RefreshFrom(p Provider) (*RateInfo, error) {
+	return api.refresh([]Provider{p})
+}
+
+func (api *ExchangeRateAPI) refresh(providers []Provider) (*RateInfo, error) {
+	previous, _ := api.cache.Load(api.base)
+	_, info, err := api.fetchTable(providers, previous)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh exchange rates: %w", err)
+	}
+	return info, nil
+}
+
+// GetRatesFrom returns the rates from p quoted against baseCurrency, with
+// the same validation as GetRates but without reading or writing the cache.
+// The rates have no expiry and are stale when older than the cache TTL.
+func (api *ExchangeRateAPI) GetRatesFrom(p Provider, baseCurrency string) (map[string]float64, *RateInfo, error) {
+	rates, info, err := api.fetchFrom(p, api.base, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", p.Name(), err)
+	}
+	info.ExpiresAt = time.Time{}
+	info.Stale = time.Since(info.Timestamp) > api.cache.ttl
+
+	return rebase(&RateTable{Base: api.base, Rates: rates, Bid: info.Bid, Ask: info.Ask}, info, baseCurrency)
+}
+
+// Base returns the canonical base currency rates are fetched and cached against.
+func (api *ExchangeRateAPI) Base() string {
+	return api.base
+}
+
+// Cache returns the cache the API reads and stores rates in.
+func (api *ExchangeRateAPI) Cache() *Cache {
+	return api.cache
+}
+
+func fromCache(cached *CacheData) (*RateTable, *RateInfo) {
+	table := &RateTable{
+		Base:  cached.Base,
+		Rates: cached.Rates,
+		Bid:   cached.Bid,
+		Ask:   cached.Ask,
+	}
+	return table, &RateInfo{
+		Source:        cached.Source,
+		Timestamp:     cached.Timestamp,
+		ExpiresAt:     cached.ExpiresAt,
+		Consensus:     cached.Consensus,
+		EffectiveDate: cached.EffectiveDate,
+		PayloadDigest: cached.PayloadDigest,
+		Quota:         cached.Quota,
+	}
 }
 
 type RateInfo struct {
 	Source    string
 	Timestamp time.Time
 	ExpiresAt time.Time
+	// Stale is set when fresh rates could not be fetched and expired ones were used.
+	Stale bool
+	// Revalidating is set when expired rates were served while a background
+	// refresh fetches new ones.
+	Revalidating bool
+	// Base is the canonical currency the rates were fetched against.
+	Base      string
+	Kinds     map[string]RateKind
+	Consensus *ConsensusReport
+	// Bid and Ask hold buy and sell quotes for providers that publish them.
+	Bid map[string]float64
+	Ask map[string]float64
+	// Mode is the rate mode used. For modes other than spot, WindowStart and
+	// WindowEnd bound the days that were averaged and Samples counts them.
+	Mode        RateMode
+	WindowStart time.Time
+	WindowEnd   time.Time
+	Samples     int
+	// Months holds, for avg-month, the averages of the twelve months ending
+	// with the window's month.
+	Months []MonthlyRates
+	// EffectiveDate is the date the provider says its rates apply to, and
+	// PayloadDigest is the SHA-256 of the response they were parsed from.
+	EffectiveDate string
+	PayloadDigest string
+	// Quota holds the rate limit and usage headers of the provider response.
+	Quota map[string]string
 }
 
-func (api *ExchangeRateAPI) fetchFromPrimary(baseCurrency string) (map[string]float64, *RateInfo, error) {
-	url := fmt.Sprintf("https://api.exchangerate-api.com/v4/latest/%s", baseCurrency)
+const (
+	primaryName  = "exchangerate-api.com"
+	fallbackName = "exchangerate.host"
+)
 
+// getJSON fetches url and decodes the JSON response into v. secret, the API
+// key embedded in url if any, is redacted from errors.
+func getJSON(url, secret string, v any) (http.Header, []byte, error) {
 	resp, err := http.Get(url)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, redact(err, secret)
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, redact(err, secret)
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+		return resp.Header, nil, fmt.Errorf("API returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, nil, err
+	if err := json.Unmarshal(body, v); err != nil {
+		return resp.Header, nil, err
 	}
+	return resp.Header, body, nil
+}
+
+func (api *ExchangeRateAPI) fetchFromPrimary(baseCurrency string) (map[string]float64, *RateInfo, error) {
+	if key := api.apiKey(primaryName); key != "" {
+		return api.fetchFromPrimaryV6(baseCurrency, key)
+	}
+
+	url := fmt.Sprintf("https://api.exchangerate-api.com/v4/latest/%s", baseCurrency)
 
 	var rateResp RateResponse
-	if err := json.Unmarshal(body, &rateResp); err != nil {
+	header, body, err := getJSON(url, "", &rateResp)
+	if err != nil {
 		return nil, nil, err
 	}
 
-	if rateResp.Rates == nil {
-		rateResp.Rates = make(map[string]float64)
+	if err := validatePayload(baseCurrency, rateResp.Base, rateResp.Rates); err != nil {
+		return nil, nil, err
 	}
 	rateResp.Rates[baseCurrency] = 1.0
 
 	return rateResp.Rates, &RateInfo{
-		Source:    "exchangerate-api.com",
-		Timestamp: time.Now(),
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+		Source:        primaryName,
+		Timestamp:     time.Now(),
+		ExpiresAt:     time.Now().Add(24 * time.Hour),
+		EffectiveDate: rateResp.Date,
+		PayloadDigest: payloadDigest(body),
+		Quota:         quotaHeaders(header),
 	}, nil
 }
 
-func (api *ExchangeRateAPI) fetchFromFallback(baseCurrency string) (map[string]float64, *RateInfo, error) {
-	url := fmt.Sprintf("https://api.exchangerate.host/latest?base=%s", baseCurrency)
+// fetchFromPrimaryV6 uses the authenticated v6 API, which takes the key in
+// the URL path.
+func (api *ExchangeRateAPI) fetchFromPrimaryV6(baseCurrency, key string) (map[string]float64, *RateInfo, error) {
+	key = url.PathEscape(key)
+	endpoint := fmt.Sprintf("https://v6.exchangerate-api.com/v6/%s/latest/%s", key, baseCurrency)
 
-	resp, err := http.Get(url)
+	var response struct {
+		Result     string             `json:"result"`
+		ErrorType  string             `json:"error-type"`
+		Base       string             `json:"base_code"`
+		Rates      map[string]float64 `json:"conversion_rates"`
+		LastUpdate int64              `json:"time_last_update_unix"`
+	}
+	header, body, err := getJSON(endpoint, key, &response)
 	if err != nil {
 		return nil, nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	if response.Result != "success" {
+		return nil, nil, fmt.Errorf("API returned error: %s", response.ErrorType)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+	if err := validatePayload(baseCurrency, response.Base, response.Rates); err != nil {
 		return nil, nil, err
 	}
+	response.Rates[baseCurrency] = 1.0
+
+	info := &RateInfo{
+		Source:        primaryName,
+		Timestamp:     time.Now(),
+		ExpiresAt:     time.Now().Add(24 * time.Hour),
+		PayloadDigest: payloadDigest(body),
+		Quota:         quotaHeaders(header),
+	}
+	if response.LastUpdate > 0 {
+		info.EffectiveDate = time.Unix(response.LastUpdate, 0).UTC().Format(dateLayout)
+	}
+	return response.Rates, info, nil
+}
+
+// hostError is the error object of the authenticated exchangerate.host API.
+type hostError struct {
+	Code int    `json:"code"`
+	Type string `json:"type"`
+	Info string `json:"info"`
+}
+
+func (e *hostError) err() error {
+	if e == nil {
+		return fmt.Errorf("API returned success=false")
+	}
+	if e.Info != "" {
+		return fmt.Errorf("API returned error %d: %s", e.Code, e.Info)
+	}
+	return fmt.Errorf("API returned error %d: %s", e.Code, e.Type)
+}
+
+// hostQuotes turns quotes keyed by pair, e.g. "EURPLN", into rates keyed by
+// the quote currency.
+func hostQuotes(source string, quotes map[string]float64) map[string]float64 {
+	rates := make(map[string]float64, len(quotes))
+	for pair, rate := range quotes {
+		rates[strings.TrimPrefix(pair, source)] = rate
+	}
+	return rates
+}
+
+func (api *ExchangeRateAPI) fetchFromFallback(baseCurrency string) (map[string]float64, *RateInfo, error) {
+	if key := api.apiKey(fallbackName); key != "" {
+		return api.fetchFromFallbackLive(baseCurrency, key)
+	}
+
+	url := fmt.Sprintf("https://api.exchangerate.host/latest?base=%s", baseCurrency)
 
 	var response struct {
 		Success bool               `json:"success"`
@@ -126,8 +475,8 @@ func (api *ExchangeRateAPI) fetchFromFallback(baseCurrency string) (map[string]f
 		Rates   map[string]float64 `json:"rates"`
 		Date    string             `json:"date"`
 	}
-
-	if err := json.Unmarshal(body, &response); err != nil {
+	header, body, err := getJSON(url, "", &response)
+	if err != nil {
 		return nil, nil, err
 	}
 
@@ -135,14 +484,146 @@ func (api *ExchangeRateAPI) fetchFromFallback(baseCurrency string) (map[string]f
 		return nil, nil, fmt.Errorf("API returned success=false")
 	}
 
-	if response.Rates == nil {
-		response.Rates = make(map[string]float64)
+	if err := validatePayload(baseCurrency, response.Base, response.Rates); err != nil {
+		return nil, nil, err
 	}
 	response.Rates[baseCurrency] = 1.0
 
 	return response.Rates, &RateInfo{
-		Source:    "exchangerate.host",
-		Timestamp: time.Now(),
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+		Source:        fallbackName,
+		Timestamp:     time.Now(),
+		ExpiresAt:     time.Now().Add(24 * time.Hour),
+		EffectiveDate: response.Date,
+		PayloadDigest: payloadDigest(body),
+		Quota:         quotaHeaders(header),
 	}, nil
 }
+
+// fetchFromFallbackLive uses the authenticated live endpoint, which quotes
+// pairs such as "EURPLN" against the source currency.
+func (api *ExchangeRateAPI) fetchFromFallbackLive(baseCurrency, key string) (map[string]float64, *RateInfo, error) {
+	key = url.QueryEscape(key)
+	endpoint := fmt.Sprintf("https://api.exchangerate.host/live?access_key=%s&source=%s", key, baseCurrency)
+
+	var response struct {
+		Success   bool               `json:"success"`
+		Error     *hostError         `json:"error"`
+		Source    string             `json:"source"`
+		Quotes    map[string]float64 `json:"quotes"`
+		Timestamp int64              `json:"timestamp"`
+	}
+	header, body, err := getJSON(endpoint, key, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !response.Success {
+		return nil, nil, response.Error.err()
+	}
+
+	rates := hostQuotes(response.Source, response.Quotes)
+	if err := validatePayload(baseCurrency, response.Source, rates); err != nil {
+		return nil, nil, err
+	}
+	rates[baseCurrency] = 1.0
+
+	info := &RateInfo{
+		Source:        fallbackName,
+		Timestamp:     time.Now(),
+		ExpiresAt:     time.Now().Add(24 * time.Hour),
+		PayloadDigest: payloadDigest(body),
+		Quota:         quotaHeaders(header),
+	}
+	if response.Timestamp > 0 {
+		info.EffectiveDate = time.Unix(response.Timestamp, 0).UTC().Format(dateLayout)
+	}
+	return rates, info, nil
+}
+
+// fetchSeriesFromFallback fetches daily rates from the exchangerate.host
+// time series endpoint, which serves at most a year per request.
+func (api *ExchangeRateAPI) fetchSeriesFromFallback(baseCurrency string, from, to time.Time) ([]HistoryEntry, error) {
+	key := api.apiKey(fallbackName)
+
+	var entries []HistoryEntry
+
+	for start := from; !start.After(to); start = start.AddDate(1, 0, 0) {
+		end := start.AddDate(1, 0, -1)
+		if end.After(to) {
+			end = to
+		}
+
+		days, err := fetchHostSeries(baseCurrency, key, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		for day, rates := range days {
+			if err := validatePayload(baseCurrency, baseCurrency, rates); err != nil {
+				return nil, fmt.Errorf("%s: %w", day, err)
+			}
+			rates[baseCurrency] = 1.0
+			entries = append(entries, HistoryEntry{
+				Date:      day,
+				Base:      baseCurrency,
+				Rates:     rates,
+				Source:    fallbackName,
+				FetchedAt: now,
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// fetchHostSeries fetches one chunk of daily rates, from the timeframe
+// endpoint when key is set and the key-less timeseries endpoint otherwise.
+func fetchHostSeries(baseCurrency, key string, start, end time.Time) (map[string]map[string]float64, error) {
+	if key == "" {
+		url := fmt.Sprintf("https://api.exchangerate.host/timeseries?base=%s&start_date=%s&end_date=%s",
+			baseCurrency, start.Format(dateLayout), end.Format(dateLayout))
+
+		var response struct {
+			Success bool                          `json:"success"`
+			Base    string                        `json:"base"`
+			Rates   map[string]map[string]float64 `json:"rates"`
+		}
+		if _, _, err := getJSON(url, "", &response); err != nil {
+			return nil, err
+		}
+		if !response.Success {
+			return nil, fmt.Errorf("API returned success=false")
+		}
+		if response.Base != "" && response.Base != baseCurrency {
+			return nil, fmt.Errorf("%w: response base %s, requested %s", ErrInvalidRates, response.Base, baseCurrency)
+		}
+		return response.Rates, nil
+	}
+
+	key = url.QueryEscape(key)
+	endpoint := fmt.Sprintf("https://api.exchangerate.host/timeframe?access_key=%s&source=%s&start_date=%s&end_date=%s",
+		key, baseCurrency, start.Format(dateLayout), end.Format(dateLayout))
+
+	var response struct {
+		Success bool                          `json:"success"`
+		Error   *hostError                    `json:"error"`
+		Source  string                        `json:"source"`
+		Quotes  map[string]map[string]float64 `json:"quotes"`
+	}
+	if _, _, err := getJSON(endpoint, key, &response); err != nil {
+		return nil, err
+	}
+	if !response.Success {
+		return nil, response.Error.err()
+	}
+	if response.Source != baseCurrency {
+		return nil, fmt.Errorf("%w: response base %s, requested %s", ErrInvalidRates, response.Source, baseCurrency)
+	}
+
+	days := make(map[string]map[string]float64, len(response.Quotes))
+	for day, quotes := range response.Quotes {
+		days[day] = hostQuotes(response.Source, quotes)
+	}
+	return days, nil
+}
@@ -0,0 +1,198 @@
+package exchangerate
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RateMode selects which rate a conversion uses: today's spot rate or one
+// derived from the historical rates of a calendar window.
+type RateMode string
+
+const (
+	RateSpot      RateMode = "spot"
+	RateAvgMonth  RateMode = "avg-month"
+	RateAvgYear   RateMode = "avg-year"
+	RatePeriodEnd RateMode = "period-end"
+)
+
+var ValidRateModes = []RateMode{RateSpot, RateAvgMonth, RateAvgYear, RatePeriodEnd}
+
+func ParseRateMode(s string) (RateMode, error) {
+	for _, mode := range ValidRateModes {
+		if string(mode) == strings.ToLower(s) {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("invalid rate mode: %s (supported: spot, avg-month, avg-year, period-end)", s)
+}
+
+// Window returns the calendar window the mode draws rates from for the
+// reference date at: its month, or its year for avg-year. The window never
+// extends past today.
+func (m RateMode) Window(at time.Time) (time.Time, time.Time) {
+	at = at.UTC()
+	from := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, -1)
+	if m == RateAvgYear {
+		from = time.Date(at.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		to = time.Date(at.Year(), time.December, 31, 0, 0, 0, 0, time.UTC)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if to.After(today) {
+		to = today
+	}
+	return from, to
+}
+
+// MonthlyRates are the average rates of one calendar month.
+type MonthlyRates struct {
+	Month   string
+	Rates   map[string]float64
+	Samples int
+}
+
+// GetPeriodRates returns rates against baseCurrency for a non-spot mode,
+// computed from the daily history of the mode's window around at. Days
+// missing from the history are backfilled first unless offline. For
+// avg-month, info.Months also holds the averages of each of the twelve months
+// ending with at's month, so that a year can be converted month by month.
+func (api *ExchangeRateAPI) GetPeriodRates(baseCurrency string, mode RateMode, at time.Time, offline bool) (map[string]float64, *RateInfo, error) {
+	from, to := mode.Window(at)
+	historyFrom := from
+	if mode == RateAvgMonth {
+		historyFrom = from.AddDate(0, -11, 0)
+	}
+
+	var backfillErr error
+	if !offline {
+		backfillErr = api.Backfill(historyFrom, to)
+	}
+
+	history, err := api.history.Days(historyFrom, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var days []HistoryEntry
+	for _, entry := range history {
+		if entry.Date >= from.Format(dateLayout) {
+			days = append(days, entry)
+		}
+	}
+	if len(days) == 0 {
+		if backfillErr != nil {
+			return nil, nil, fmt.Errorf("no rate history between %s and %s: %w",
+				from.Format(dateLayout), to.Format(dateLayout), backfillErr)
+		}
+		return nil, nil, fmt.Errorf("no rate history between %s and %s",
+			from.Format(dateLayout), to.Format(dateLayout))
+	}
+
+	if mode == RatePeriodEnd {
+		days = days[len(days)-1:]
+	}
+
+	rates, sources, fetchedAt := averageDays(days, baseCurrency)
+	if len(rates) == 0 {
+		return nil, nil, fmt.Errorf("no %s rates in history between %s and %s",
+			baseCurrency, from.Format(dateLayout), to.Format(dateLayout))
+	}
+
+	kinds := make(map[string]RateKind, len(rates))
+	for currency := range rates {
+		kinds[currency] = RateDerived
+	}
+	kinds[baseCurrency] = RateDirect
+
+	first, _ := time.Parse(dateLayout, days[0].Date)
+	last, _ := time.Parse(dateLayout, days[len(days)-1].Date)
+
+	info := &RateInfo{
+		Source:      strings.Join(sources, ", "),
+		Timestamp:   fetchedAt,
+		Base:        api.base,
+		Kinds:       kinds,
+		Mode:        mode,
+		WindowStart: first,
+		WindowEnd:   last,
+		Samples:     len(days),
+	}
+
+	if mode == RateAvgMonth {
+		if info.Months, err = monthlyAverages(history, baseCurrency, historyFrom); err != nil {
+			if backfillErr != nil {
+				err = fmt.Errorf("%w: %w", err, backfillErr)
+			}
+			return nil, nil, err
+		}
+	}
+	return rates, info, nil
+}
+
+// monthlyAverages averages history by calendar month for the twelve months
+// starting with from. Every month must have at least one day of rates.
+func monthlyAverages(history []HistoryEntry, baseCurrency string, from time.Time) ([]MonthlyRates, error) {
+	byMonth := make(map[string][]HistoryEntry)
+	for _, entry := range history {
+		month := entry.Date[:len("2006-01")]
+		byMonth[month] = append(byMonth[month], entry)
+	}
+
+	months := make([]MonthlyRates, 0, 12)
+	var missing []string
+	for i := 0; i < 12; i++ {
+		month := from.AddDate(0, i, 0).Format("2006-01")
+		rates, _, _ := averageDays(byMonth[month], baseCurrency)
+		if len(rates) == 0 {
+			missing = append(missing, month)
+			continue
+		}
+		months = append(months, MonthlyRates{Month: month, Rates: rates, Samples: len(byMonth[month])})
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no %s rate history for %s (avg-month converts the Year row month by month)",
+			baseCurrency, strings.Join(missing, ", "))
+	}
+	return months, nil
+}
+
+// averageDays returns the mean rates of days against baseCurrency, with the
+// sources they came from and when the latest was fetched. Averaging cross
+// rates per day keeps each pair's mean exact rather than dividing two
+// averaged legs.
+func averageDays(days []HistoryEntry, baseCurrency string) (map[string]float64, []string, time.Time) {
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	var sources []string
+	var fetchedAt time.Time
+
+	for _, entry := range days {
+		table := &RateTable{Base: entry.Base, Rates: entry.Rates}
+		rates, _, err := table.Rebase(baseCurrency)
+		if err != nil {
+			continue
+		}
+		for currency, rate := range rates {
+			sums[currency] += rate
+			counts[currency]++
+		}
+		if !slices.Contains(sources, entry.Source) {
+			sources = append(sources, entry.Source)
+		}
+		if entry.FetchedAt.After(fetchedAt) {
+			fetchedAt = entry.FetchedAt
+		}
+	}
+
+	rates := make(map[string]float64, len(sums))
+	for currency, sum := range sums {
+		rates[currency] = sum / float64(counts[currency])
+	}
+	sort.Strings(sources)
+	return rates, sources, fetchedAt
+}
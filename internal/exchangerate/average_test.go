@@ -0,0 +1,84 @@
+package exchangerate
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"salary-calc/internal/converter"
+)
+
+func TestGetPeriodRatesAvgMonthConvertsEachMonth(t *testing.T) {
+	api := newTestAPI()
+	fetched := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	add := func(date string, pln float64) {
+		t.Helper()
+		entry := HistoryEntry{Date: date, Base: "EUR", Rates: map[string]float64{"EUR": 1, "PLN": pln, "USD": 1.1, "GBP": 0.85}, Source: "test", FetchedAt: fetched}
+		if err := api.history.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// January to October at 4.0, November at 4.2, and December averaging
+	// 4.5 over two days.
+	for month := 1; month <= 10; month++ {
+		add(fmt.Sprintf("2025-%02d-10", month), 4.0)
+	}
+	add("2025-11-10", 4.2)
+	add("2025-12-10", 4.4)
+	add("2025-12-11", 4.6)
+
+	rates, info, err := api.GetPeriodRates("EUR", RateAvgMonth, time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(rates["PLN"]-4.5) > 1e-9 || info.Samples != 2 {
+		t.Errorf("December PLN = %v over %d days, want 4.5 over 2", rates["PLN"], info.Samples)
+	}
+	if len(info.Months) != 12 || info.Months[0].Month != "2025-01" || info.Months[11].Month != "2025-12" {
+		t.Fatalf("months = %+v, want 2025-01 to 2025-12", info.Months)
+	}
+
+	conv := converter.NewConverter(rates, "EUR")
+	var months []map[string]float64
+	for _, month := range info.Months {
+		months = append(months, month.Rates)
+	}
+	conv.SetMonthlyRates(months)
+
+	results, err := conv.Convert(converter.Input{Amount: 1000, Period: converter.PeriodMonth, Currency: converter.CurrencyEUR})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := results[converter.PeriodMonth][converter.CurrencyPLN]; math.Abs(got-4500) > 1e-6 {
+		t.Errorf("Month PLN = %v, want 4500 at the December average", got)
+	}
+	if got := results[converter.PeriodYear][converter.CurrencyPLN]; math.Abs(got-(10*4000+4200+4500)) > 1e-6 {
+		t.Errorf("Year PLN = %v, want 48700 from the monthly averages", got)
+	}
+	if got := results[converter.PeriodYear][converter.CurrencyEUR]; math.Abs(got-12000) > 1e-6 {
+		t.Errorf("Year EUR = %v, want 12000", got)
+	}
+}
+
+func TestGetPeriodRatesAvgMonthNeedsEveryMonth(t *testing.T) {
+	api := newTestAPI()
+	for _, date := range []string{"2025-11-10", "2025-12-10"} {
+		entry := HistoryEntry{Date: date, Base: "EUR", Rates: map[string]float64{"PLN": 4.2}, Source: "test"}
+		if err := api.history.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, _, err := api.GetPeriodRates("EUR", RateAvgMonth, time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC), true)
+	if err == nil || !strings.Contains(err.Error(), "2025-01") || strings.Contains(err.Error(), "2025-11") {
+		t.Errorf("GetPeriodRates() error = %v, want the months without history", err)
+	}
+
+	// Other modes only need their own window.
+	if _, _, err := api.GetPeriodRates("EUR", RatePeriodEnd, time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC), true); err != nil {
+		t.Errorf("period-end: %v", err)
+	}
+}
@@ -5,23 +5,40 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
 type CacheData struct {
+	Version   int                `json:"version"`
 	Base      string             `json:"base"`
 	Rates     map[string]float64 `json:"rates"`
 	Timestamp time.Time          `json:"timestamp"`
 	Source    string             `json:"source"`
 	ExpiresAt time.Time          `json:"expires_at"`
+	Consensus *ConsensusReport   `json:"consensus,omitempty"`
+	Bid       map[string]float64 `json:"bid,omitempty"`
+	Ask       map[string]float64 `json:"ask,omitempty"`
+	// EffectiveDate is the date the provider says its rates apply to, and
+	// PayloadDigest identifies the raw response they were parsed from.
+	EffectiveDate string `json:"effective_date,omitempty"`
+	PayloadDigest string `json:"payload_digest,omitempty"`
+	// Quota holds the rate limit and usage headers of that response.
+	Quota map[string]string `json:"quota,omitempty"`
+	// Checksum is the SHA-256 of the file content without this field.
+	Checksum string `json:"checksum"`
 }
 
 type Cache struct {
 	cacheDir string
+	store    Store
+	backend  Backend
 	ttl      time.Duration
 }
 
-func NewCache() (*Cache, error) {
+// NewCache opens the cache with the given backend. S_CALC_CACHE_BACKEND,
+// when set, takes precedence; an empty backend means the file backend.
+func NewCache(backend Backend) (*Cache, error) {
 	cacheDir, err := getCacheDir()
 	if err != nil {
 		return nil, err
@@ -31,6 +48,20 @@ func NewCache() (*Cache, error) {
 		return nil, fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
+	if env := os.Getenv("S_CALC_CACHE_BACKEND"); env != "" {
+		if backend, err = ParseBackend(env); err != nil {
+			return nil, err
+		}
+	}
+	if backend == "" {
+		backend = BackendFile
+	}
+
+	store, err := OpenStore(backend, cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
 	ttlHours := 24
 	if ttlEnv := os.Getenv("S_CALC_CACHE_TTL"); ttlEnv != "" {
 		if parsed, err := time.ParseDuration(ttlEnv + "h"); err == nil {
@@ -40,6 +71,8 @@ func NewCache() (*Cache, error) {
 
 	return &Cache{
 		cacheDir: cacheDir,
+		store:    store,
+		backend:  backend,
 		ttl:      time.Duration(ttlHours) * time.Hour,
 	}, nil
 }
@@ -61,49 +94,142 @@ func getCacheDir() (string, error) {
 	return filepath.Join(homeDir, ".cache", "s-calc"), nil
 }
 
+// Dir returns the directory cache files are stored in.
+func (c *Cache) Dir() string {
+	return c.cacheDir
+}
+
+// Backend returns the store backend the cache uses.
+func (c *Cache) Backend() Backend {
+	return c.backend
+}
+
+func ratesKey(baseCurrency string) string {
+	return "rates/" + baseCurrency
+}
+
 func (c *Cache) Get(baseCurrency string) (*CacheData, error) {
-	cacheFile := filepath.Join(c.cacheDir, fmt.Sprintf("rates-%s.json", baseCurrency))
+	cacheData, err := c.Load(baseCurrency)
+	if err != nil || cacheData == nil {
+		return nil, err
+	}
+
+	if time.Now().After(cacheData.ExpiresAt) {
+		return nil, nil
+	}
+
+	return cacheData, nil
+}
 
-	data, err := os.ReadFile(cacheFile)
+// Load returns the cached rates for baseCurrency even if they have expired.
+func (c *Cache) Load(baseCurrency string) (*CacheData, error) {
+	data, err := c.store.Get(ratesKey(baseCurrency))
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil 
-		}
 		return nil, fmt.Errorf("failed to read cache file: %w", err)
 	}
+	if data == nil {
+		return nil, nil
+	}
 
-	var cacheData CacheData
-	if err := json.Unmarshal(data, &cacheData); err != nil {
+	cacheData, migrated, err := decodeCacheData(data, baseCurrency)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse cache file: %w", err)
 	}
 
-	if time.Now().After(cacheData.ExpiresAt) {
-		return nil, nil
+	if migrated {
+		// Best effort: a file that cannot be upgraded is migrated again next time.
+		_ = c.write(cacheData)
 	}
 
-	return &cacheData, nil
+	return cacheData, nil
 }
 
 func (c *Cache) Set(baseCurrency string, rates map[string]float64, source string) error {
-	cacheFile := filepath.Join(c.cacheDir, fmt.Sprintf("rates-%s.json", baseCurrency))
+	return c.Put(&CacheData{
+		Base:   baseCurrency,
+		Rates:  rates,
+		Source: source,
+	})
+}
 
-	now := time.Now()
-	cacheData := CacheData{
-		Base:      baseCurrency,
-		Rates:     rates,
-		Timestamp: now,
-		Source:    source,
-		ExpiresAt: now.Add(c.ttl),
-	}
+// Put stores cacheData under its base currency, stamping it with the current
+// time and TTL.
+func (c *Cache) Put(cacheData *CacheData) error {
+	return c.PutAt(cacheData, time.Now())
+}
+
+// PutAt is like Put for rates that date from timestamp, such as an imported
+// file: they expire one TTL after timestamp rather than after now.
+func (c *Cache) PutAt(cacheData *CacheData, timestamp time.Time) error {
+	cacheData.Timestamp = timestamp
+	cacheData.ExpiresAt = timestamp.Add(c.ttl)
+
+	return c.write(cacheData)
+}
+
+// write stores cacheData as the current schema version, replacing the
+// previous file atomically.
+func (c *Cache) write(cacheData *CacheData) error {
+	cacheData.Version = CacheSchemaVersion
+	cacheData.Checksum = cacheData.checksum()
 
 	data, err := json.MarshalIndent(cacheData, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal cache data: %w", err)
 	}
 
-	if err := os.WriteFile(cacheFile, data, 0644); err != nil {
+	if err := c.store.Put(ratesKey(cacheData.Base), data); err != nil {
 		return fmt.Errorf("failed to write cache file: %w", err)
 	}
 
 	return nil
 }
+
+// CacheEntry describes one cached rate set. Path, Size and ModTime describe
+// the file behind it with the file backend; with other backends Path is the
+// store key and ModTime is zero. Data is nil and Err is set when the entry
+// could not be read.
+type CacheEntry struct {
+	Base    string
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Data    *CacheData
+	Err     error
+}
+
+// List returns every cached rate set, sorted by base currency.
+func (c *Cache) List() ([]CacheEntry, error) {
+	keys, err := c.store.Keys("rates/")
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]CacheEntry, 0, len(keys))
+	for _, key := range keys {
+		entry := CacheEntry{
+			Base: strings.TrimPrefix(key, "rates/"),
+			Path: key,
+		}
+		if fs, ok := c.store.(*fileStore); ok {
+			path, info, err := fs.stat(key)
+			if err != nil {
+				continue
+			}
+			entry.Path, entry.Size, entry.ModTime = path, info.Size(), info.ModTime()
+		} else if data, err := c.store.Get(key); err == nil {
+			entry.Size = int64(len(data))
+		}
+		entry.Data, entry.Err = c.Load(entry.Base)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Remove deletes the cached rates for baseCurrency.
+func (c *Cache) Remove(baseCurrency string) error {
+	if err := c.store.Delete(ratesKey(baseCurrency)); err != nil {
+		return fmt.Errorf("failed to remove cache file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package exchangerate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultConsensusThreshold is the deviation from the median, in percent,
+// above which a provider quote is flagged.
+const DefaultConsensusThreshold = 0.5
+
+// ConsensusReport describes how closely the configured providers agreed.
+type ConsensusReport struct {
+	Providers  []string           `json:"providers"`
+	Failed     map[string]string  `json:"failed,omitempty"`
+	Threshold  float64            `json:"threshold_pct"`
+	Spread     map[string]float64 `json:"spread_pct"`
+	Deviations []Deviation        `json:"deviations,omitempty"`
+	// SingleProvider is set when only one provider answered, so that the
+	// rates were not cross-checked at all.
+	SingleProvider bool `json:"single_provider,omitempty"`
+	// Quotes holds every provider's rates, so that deviations can be
+	// flagged again against a different threshold.
+	Quotes map[string]map[string]float64 `json:"quotes,omitempty"`
+}
+
+// Deviation is a provider quote that is further from the median than the threshold.
+type Deviation struct {
+	Provider string  `json:"provider"`
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+	Median   float64 `json:"median"`
+	Percent  float64 `json:"deviation_pct"`
+}
+
+type providerQuote struct {
+	provider string
+	rates    map[string]float64
+	err      error
+}
+
+// GetConsensusRates queries all providers concurrently and returns the median
+// of their quotes, along with a report of the spread between them.
+func (api *ExchangeRateAPI) GetConsensusRates(baseCurrency string, threshold float64) (map[string]float64, *RateInfo, error) {
+	base := api.base
+
+	if cached, err := api.cache.Get(base); err == nil && cached != nil && cached.Consensus != nil {
+		api.metrics.cacheLookup(true)
+		api.logger.Debug("cache hit", "base", base, "source", cached.Source, "expires_at", cached.ExpiresAt)
+		table, info := fromCache(cached)
+		info.Consensus = cached.Consensus.withThreshold(cached.Rates, threshold)
+		return rebase(table, info, baseCurrency)
+	}
+	api.metrics.cacheLookup(false)
+	api.logger.Debug("cache has no fresh consensus rates", "base", base)
+
+	previous, _ := api.cache.Load(base)
+	quotes := api.fetchAll(base, previous)
+	rates, report, err := buildConsensus(quotes, threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+	if report.SingleProvider {
+		api.logger.Warn("consensus rates come from a single provider", "base", base, "provider", report.Providers[0])
+	}
+
+	source := fmt.Sprintf("consensus (%s)", strings.Join(report.Providers, ", "))
+	now := time.Now()
+	api.store(&CacheData{
+		Base:      base,
+		Rates:     rates,
+		Source:    source,
+		Consensus: report,
+	}, now)
+
+	table := &RateTable{Base: base, Rates: rates}
+	return rebase(table, &RateInfo{
+		Source:    source,
+		Timestamp: now,
+		ExpiresAt: now.Add(api.cache.ttl),
+		Consensus: report,
+	}, baseCurrency)
+}
+
+func (api *ExchangeRateAPI) fetchAll(base string, previous *CacheData) []providerQuote {
+	quotes := make([]providerQuote, len(api.providers))
+
+	var wg sync.WaitGroup
+	for i, p := range api.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rates, _, err := api.fetchFrom(p, base, previous)
+			quotes[i] = providerQuote{provider: p.Name(), rates: rates, err: err}
+		}()
+	}
+	wg.Wait()
+
+	return quotes
+}
+
+func buildConsensus(quotes []providerQuote, threshold float64) (map[string]float64, *ConsensusReport, error) {
+	report := &ConsensusReport{
+		Threshold: threshold,
+		Spread:    make(map[string]float64),
+		Quotes:    make(map[string]map[string]float64),
+	}
+
+	byCurrency := make(map[string][]float64)
+	var ok []providerQuote
+	for _, q := range quotes {
+		if q.err != nil {
+			if report.Failed == nil {
+				report.Failed = make(map[string]string)
+			}
+			report.Failed[q.provider] = q.err.Error()
+			continue
+		}
+		ok = append(ok, q)
+		report.Providers = append(report.Providers, q.provider)
+		report.Quotes[q.provider] = q.rates
+		for currency, rate := range q.rates {
+			byCurrency[currency] = append(byCurrency[currency], rate)
+		}
+	}
+
+	if len(ok) == 0 {
+		var failures []string
+		for _, q := range quotes {
+			failures = append(failures, fmt.Sprintf("%s: %v", q.provider, q.err))
+		}
+		return nil, nil, fmt.Errorf("all providers failed: %s", strings.Join(failures, "; "))
+	}
+
+	rates := make(map[string]float64, len(byCurrency))
+	for currency, values := range byCurrency {
+		m := median(values)
+		rates[currency] = m
+		if m > 0 {
+			lo, hi := minMax(values)
+			report.Spread[currency] = (hi - lo) / m * 100
+		}
+	}
+
+	report.SingleProvider = len(ok) == 1
+	report.flagDeviations(rates)
+	return rates, report, nil
+}
+
+// withThreshold returns a copy of a cached report with its deviations flagged
+// against threshold. Reports cached without quotes are returned as stored.
+func (r *ConsensusReport) withThreshold(medians map[string]float64, threshold float64) *ConsensusReport {
+	if len(r.Quotes) == 0 || r.Threshold == threshold {
+		return r
+	}
+	report := *r
+	report.Threshold = threshold
+	report.Deviations = nil
+	report.flagDeviations(medians)
+	return &report
+}
+
+// flagDeviations records every provider quote further than the threshold
+// from the median.
+func (r *ConsensusReport) flagDeviations(medians map[string]float64) {
+	for provider, quotes := range r.Quotes {
+		for currency, rate := range quotes {
+			m := medians[currency]
+			if m <= 0 {
+				continue
+			}
+			deviation := (rate - m) / m * 100
+			if math.Abs(deviation) > r.Threshold {
+				r.Deviations = append(r.Deviations, Deviation{
+					Provider: provider,
+					Currency: currency,
+					Rate:     rate,
+					Median:   m,
+					Percent:  deviation,
+				})
+			}
+		}
+	}
+
+	sort.Slice(r.Deviations, func(i, j int) bool {
+		a, b := r.Deviations[i], r.Deviations[j]
+		if a.Currency != b.Currency {
+			return a.Currency < b.Currency
+		}
+		return a.Provider < b.Provider
+	})
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func minMax(values []float64) (float64, float64) {
+	lo, hi := values[0], values[0]
+	for _, v := range values[1:] {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	return lo, hi
+}
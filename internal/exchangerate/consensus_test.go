@@ -0,0 +1,57 @@
+package exchangerate
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuildConsensus(t *testing.T) {
+	quotes := []providerQuote{
+		{provider: "a", rates: map[string]float64{"PLN": 4.00}},
+		{provider: "b", rates: map[string]float64{"PLN": 4.02}},
+		{provider: "c", rates: map[string]float64{"PLN": 4.20}},
+	}
+
+	rates, report, err := buildConsensus(quotes, DefaultConsensusThreshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rates["PLN"] != 4.02 {
+		t.Errorf("median = %v, want 4.02", rates["PLN"])
+	}
+	if report.SingleProvider {
+		t.Error("SingleProvider set for three providers")
+	}
+	if len(report.Deviations) != 1 || report.Deviations[0].Provider != "c" {
+		t.Errorf("deviations = %+v, want only c", report.Deviations)
+	}
+
+	// A cached report is flagged again against a looser or stricter threshold.
+	if got := report.withThreshold(rates, 5).Deviations; len(got) != 0 {
+		t.Errorf("deviations at 5%% = %+v, want none", got)
+	}
+	if got := report.withThreshold(rates, 0.1).Deviations; len(got) != 2 {
+		t.Errorf("deviations at 0.1%% = %+v, want a and c", got)
+	}
+	if len(report.Deviations) != 1 {
+		t.Error("withThreshold modified the cached report")
+	}
+}
+
+func TestBuildConsensusSingleProvider(t *testing.T) {
+	quotes := []providerQuote{
+		{provider: "a", rates: map[string]float64{"PLN": 4.00}},
+		{provider: "b", err: errors.New("timeout")},
+	}
+
+	_, report, err := buildConsensus(quotes, DefaultConsensusThreshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.SingleProvider {
+		t.Error("SingleProvider not set when one provider answered")
+	}
+	if report.Failed["b"] != "timeout" {
+		t.Errorf("failed = %v", report.Failed)
+	}
+}
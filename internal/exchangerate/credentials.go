@@ -0,0 +1,84 @@
+package exchangerate
+
+import (
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// keyEnv names the environment variable holding each provider's API key.
+var keyEnv = map[string]string{
+	primaryName:  "S_CALC_EXCHANGERATE_API_KEY",
+	fallbackName: "S_CALC_EXCHANGERATE_HOST_KEY",
+}
+
+// SetAPIKeys sets the API keys by provider name, e.g. from the config or
+// credentials file. Keys in the environment take precedence.
+func (api *ExchangeRateAPI) SetAPIKeys(keys map[string]string) {
+	api.apiKeys = keys
+}
+
+// apiKey returns the key for the named provider, or "" to use its free tier.
+func (api *ExchangeRateAPI) apiKey(provider string) string {
+	if name := keyEnv[provider]; name != "" {
+		if key := os.Getenv(name); key != "" {
+			return key
+		}
+	}
+	return api.apiKeys[provider]
+}
+
+// redactedError hides an API key that ended up in an error message, such as
+// the request URL of a *url.Error.
+type redactedError struct {
+	err    error
+	secret string
+}
+
+func (e *redactedError) Error() string {
+	return strings.ReplaceAll(e.err.Error(), e.secret, "****")
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+func redact(err error, secret string) error {
+	if err == nil || secret == "" {
+		return err
+	}
+	return &redactedError{err: err, secret: secret}
+}
+
+// quotaPrefixes are the header prefixes providers report rate limits and
+// usage with.
+var quotaPrefixes = []string{"X-Ratelimit-", "Ratelimit-", "X-Quota-", "X-Api-Quota-", "X-Requests-"}
+
+// quotaHeaders returns the rate limit and usage headers of a response, or
+// nil if it has none.
+func quotaHeaders(header http.Header) map[string]string {
+	var quota map[string]string
+	for name, values := range header {
+		for _, prefix := range quotaPrefixes {
+			if strings.HasPrefix(name, prefix) && len(values) > 0 {
+				if quota == nil {
+					quota = make(map[string]string)
+				}
+				quota[name] = values[0]
+				break
+			}
+		}
+	}
+	return quota
+}
+
+// QuotaNames returns the quota header names in order.
+func QuotaNames(quota map[string]string) []string {
+	names := make([]string, 0, len(quota))
+	for name := range quota {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
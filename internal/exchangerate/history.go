@@ -0,0 +1,411 @@
+package exchangerate
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// HistoryEntry is one rate set recorded in the history log.
+type HistoryEntry struct {
+	Date      string             `json:"date"`
+	Base      string             `json:"base"`
+	Rates     map[string]float64 `json:"rates"`
+	Source    string             `json:"source"`
+	FetchedAt time.Time          `json:"fetched_at"`
+}
+
+// Point is a single dated rate in a series.
+type Point struct {
+	Date time.Time
+	Rate float64
+}
+
+// History is an append-only log of every rate set fetched, kept apart from
+// the TTL cache so that entries are never overwritten. It is a JSON lines
+// file, or one store key per entry when the cache uses another backend.
+type History struct {
+	path  string
+	store Store
+}
+
+func NewHistory(dir string) *History {
+	return &History{path: filepath.Join(dir, "history.jsonl")}
+}
+
+// NewStoreHistory keeps the history in store under "history/" keys.
+func NewStoreHistory(store Store) *History {
+	return &History{store: store}
+}
+
+func newCacheHistory(cache *Cache) *History {
+	if _, ok := cache.store.(*fileStore); ok {
+		return NewHistory(cache.cacheDir)
+	}
+	return NewStoreHistory(cache.store)
+}
+
+// historyKey orders entries by day, then by fetch time.
+func historyKey(entry HistoryEntry) string {
+	return fmt.Sprintf("history/%s/%s/%s", entry.Date, entry.FetchedAt.UTC().Format(time.RFC3339Nano), entry.Base)
+}
+
+// Append adds entries to the end of the log.
+func (h *History) Append(entries ...HistoryEntry) error {
+	if h.store != nil {
+		for _, entry := range entries {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return fmt.Errorf("failed to marshal history entry: %w", err)
+			}
+			if err := h.store.Put(historyKey(entry), data); err != nil {
+				return fmt.Errorf("failed to write history: %w", err)
+			}
+		}
+		return nil
+	}
+
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal history entry: %w", err)
+		}
+		w.Write(data)
+		w.WriteByte('\n')
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return nil
+}
+
+// Entries reads the whole log. Lines that cannot be parsed are skipped.
+func (h *History) Entries() ([]HistoryEntry, error) {
+	if h.store != nil {
+		return h.storeEntries("history/", nil)
+	}
+
+	f, err := os.Open(h.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	return entries, nil
+}
+
+// between returns the entries dated fromDay to toDay. With a store only
+// those keys are read; the log file has to be read in full.
+func (h *History) between(fromDay, toDay string) ([]HistoryEntry, error) {
+	if h.store == nil {
+		return h.Entries()
+	}
+	return h.storeEntries("history/", func(key string) bool {
+		day := strings.SplitN(strings.TrimPrefix(key, "history/"), "/", 2)[0]
+		return day >= fromDay && day <= toDay
+	})
+}
+
+func (h *History) storeEntries(prefix string, include func(key string) bool) ([]HistoryEntry, error) {
+	keys, err := h.store.Keys(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	entries := make([]HistoryEntry, 0, len(keys))
+	for _, key := range keys {
+		if include != nil && !include(key) {
+			continue
+		}
+		data, err := h.store.Get(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+		var entry HistoryEntry
+		if data == nil || json.Unmarshal(data, &entry) != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Series returns one rate per day for quote against base between from and to,
+// inclusive. Entries in any base are triangulated.
+func (h *History) Series(base, quote string, from, to time.Time) ([]Point, error) {
+	days, err := h.Days(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []Point
+	for _, entry := range days {
+		rate, ok := crossRate(entry, base, quote)
+		if !ok {
+			continue
+		}
+		date, err := time.Parse(dateLayout, entry.Date)
+		if err != nil {
+			continue
+		}
+		points = append(points, Point{Date: date, Rate: rate})
+	}
+	return points, nil
+}
+
+// Days returns one entry per recorded day between from and to, inclusive,
+// sorted by date. The most recently fetched entry wins when a day was
+// recorded more than once.
+func (h *History) Days(from, to time.Time) ([]HistoryEntry, error) {
+	fromDay := from.Format(dateLayout)
+	toDay := to.Format(dateLayout)
+
+	entries, err := h.between(fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]HistoryEntry)
+	for _, entry := range entries {
+		if entry.Date < fromDay || entry.Date > toDay {
+			continue
+		}
+		if prev, ok := latest[entry.Date]; ok && prev.FetchedAt.After(entry.FetchedAt) {
+			continue
+		}
+		latest[entry.Date] = entry
+	}
+
+	days := make([]HistoryEntry, 0, len(latest))
+	for _, entry := range latest {
+		days = append(days, entry)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date < days[j].Date
+	})
+	return days, nil
+}
+
+func crossRate(entry HistoryEntry, base, quote string) (float64, bool) {
+	rateOf := func(currency string) (float64, bool) {
+		if currency == entry.Base {
+			return 1.0, true
+		}
+		rate, ok := entry.Rates[currency]
+		return rate, ok && rate > 0
+	}
+
+	baseRate, okBase := rateOf(base)
+	quoteRate, okQuote := rateOf(quote)
+	if !okBase || !okQuote {
+		return 0, false
+	}
+	return quoteRate / baseRate, true
+}
+
+// record appends a fetched rate set to the history under day.
+func (api *ExchangeRateAPI) record(day, base string, rates map[string]float64, source string) {
+	now := time.Now()
+	err := api.history.Append(HistoryEntry{
+		Date:      day,
+		Base:      base,
+		Rates:     rates,
+		Source:    source,
+		FetchedAt: now,
+	})
+	if err != nil {
+		api.logger.Warn("history write failed", "base", base, "error", err)
+	}
+}
+
+// RateHistory returns the daily series of quote against base. With backfill,
+// days missing from the local history are first fetched from a provider that
+// supports time series.
+func (api *ExchangeRateAPI) RateHistory(base, quote string, from, to time.Time, backfill bool) ([]Point, error) {
+	if backfill {
+		if err := api.Backfill(from, to); err != nil {
+			return nil, err
+		}
+	}
+	return api.history.Series(base, quote, from, to)
+}
+
+// Backfill fetches the canonical rates for the days between from and to
+// that the history lacks. Only past weekdays count as missing, as there are
+// no rates for weekends or yet for today, and days already requested from a
+// provider are not requested again, so that holidays do not cause a fetch on
+// every call. Series providers are tried in order until one succeeds.
+func (api *ExchangeRateAPI) Backfill(from, to time.Time) error {
+	known, err := api.history.Entries()
+	if err != nil {
+		return err
+	}
+
+	recorded := make(map[string]bool)
+	for _, entry := range known {
+		if entry.Base == api.base {
+			recorded[entry.Date] = true
+		}
+	}
+
+	requested, err := api.requestedDays()
+	if err != nil {
+		api.logger.Warn("backfill record unreadable", "base", api.base, "error", err)
+	}
+
+	today := time.Now().UTC().Format(dateLayout)
+	var first, last time.Time
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		if date >= today || day.Weekday() == time.Saturday || day.Weekday() == time.Sunday ||
+			recorded[date] || requested.contains(date) {
+			continue
+		}
+		if first.IsZero() {
+			first = day
+		}
+		last = day
+	}
+	if first.IsZero() {
+		return nil
+	}
+
+	var errs []error
+	for _, p := range api.providers {
+		sp, ok := p.(SeriesProvider)
+		if !ok {
+			continue
+		}
+
+		entries, err := sp.FetchSeries(api.base, first, last)
+		if err != nil {
+			api.logger.Info("series provider failed", "provider", sp.Name(), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", sp.Name(), err))
+			continue
+		}
+
+		var fresh []HistoryEntry
+		for _, entry := range entries {
+			if !recorded[entry.Date] {
+				fresh = append(fresh, entry)
+			}
+		}
+		sort.Slice(fresh, func(i, j int) bool {
+			return fresh[i].Date < fresh[j].Date
+		})
+		if err := api.history.Append(fresh...); err != nil {
+			return err
+		}
+
+		requested = append(requested, dayRange{From: first.Format(dateLayout), To: last.Format(dateLayout)})
+		if err := api.saveRequestedDays(requested); err != nil {
+			api.logger.Warn("backfill record write failed", "base", api.base, "error", err)
+		}
+		return nil
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return fmt.Errorf("no configured provider supports historical rates")
+}
+
+// dayRange is an inclusive range of YYYY-MM-DD days.
+type dayRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// dayRanges are the days already requested from a series provider.
+type dayRanges []dayRange
+
+func (r dayRanges) contains(date string) bool {
+	for _, days := range r {
+		if date >= days.From && date <= days.To {
+			return true
+		}
+	}
+	return false
+}
+
+// merged returns the ranges sorted, with overlapping and adjacent ranges
+// joined.
+func (r dayRanges) merged() dayRanges {
+	sorted := append(dayRanges(nil), r...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].From < sorted[j].From
+	})
+
+	var merged dayRanges
+	for _, days := range sorted {
+		if n := len(merged); n > 0 {
+			end, _ := time.Parse(dateLayout, merged[n-1].To)
+			if days.From <= end.AddDate(0, 0, 1).Format(dateLayout) {
+				if days.To > merged[n-1].To {
+					merged[n-1].To = days.To
+				}
+				continue
+			}
+		}
+		merged = append(merged, days)
+	}
+	return merged
+}
+
+func backfillKey(base string) string {
+	return "backfill/" + base
+}
+
+// requestedDays returns the days already requested for the canonical base.
+func (api *ExchangeRateAPI) requestedDays() (dayRanges, error) {
+	data, err := api.cache.store.Get(backfillKey(api.base))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var ranges dayRanges
+	if err := json.Unmarshal(data, &ranges); err != nil {
+		return nil, err
+	}
+	return ranges, nil
+}
+
+func (api *ExchangeRateAPI) saveRequestedDays(ranges dayRanges) error {
+	data, err := json.Marshal(ranges.merged())
+	if err != nil {
+		return err
+	}
+	return api.cache.store.Put(backfillKey(api.base), data)
+}
@@ -0,0 +1,115 @@
+package exchangerate
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeSeries serves a rate for every weekday except holidays.
+type fakeSeries struct {
+	name     string
+	err      error
+	holidays map[string]bool
+	calls    [][2]string
+}
+
+func (p *fakeSeries) Name() string { return p.name }
+
+func (p *fakeSeries) Fetch(string) (map[string]float64, *RateInfo, error) {
+	return nil, nil, errors.New("not used")
+}
+
+func (p *fakeSeries) FetchSeries(base string, from, to time.Time) ([]HistoryEntry, error) {
+	p.calls = append(p.calls, [2]string{from.Format(dateLayout), to.Format(dateLayout)})
+	if p.err != nil {
+		return nil, p.err
+	}
+	var entries []HistoryEntry
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday || p.holidays[date] {
+			continue
+		}
+		entries = append(entries, HistoryEntry{Date: date, Base: base, Rates: map[string]float64{"PLN": 4.25}, Source: p.name})
+	}
+	return entries, nil
+}
+
+func TestBackfillSkipsWeekendsAndRequestedDays(t *testing.T) {
+	api := newTestAPI()
+	series := &fakeSeries{name: "series", holidays: map[string]bool{"2026-01-01": true}}
+	api.providers = []Provider{series}
+
+	// Thursday 2025-12-25 to Sunday 2026-01-04, with two holidays and a
+	// weekend at each end.
+	from := time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)
+	series.holidays["2025-12-25"] = true
+	if err := api.Backfill(from, to); err != nil {
+		t.Fatal(err)
+	}
+	if len(series.calls) != 1 || series.calls[0] != [2]string{"2025-12-25", "2026-01-02"} {
+		t.Fatalf("calls = %v, want one for the weekdays 2025-12-25 to 2026-01-02", series.calls)
+	}
+	days, _ := api.history.Days(from, to)
+	if len(days) != 5 {
+		t.Errorf("history has %d days, want 5 weekdays without the holidays", len(days))
+	}
+
+	// The holidays and weekends are not requested again.
+	if err := api.Backfill(from, to); err != nil {
+		t.Fatal(err)
+	}
+	if len(series.calls) != 1 {
+		t.Errorf("calls = %v, want no new request", series.calls)
+	}
+
+	// Today never counts as missing.
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if err := api.Backfill(today, today); err != nil {
+		t.Fatal(err)
+	}
+	if len(series.calls) != 1 {
+		t.Errorf("calls = %v, want no request for today", series.calls)
+	}
+}
+
+func TestBackfillTriesNextSeriesProvider(t *testing.T) {
+	api := newTestAPI()
+	down := &fakeSeries{name: "down", err: errors.New("timeout")}
+	up := &fakeSeries{name: "up"}
+	api.providers = []Provider{down, up}
+
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC) // a Monday
+	if err := api.Backfill(day, day); err != nil {
+		t.Fatal(err)
+	}
+	if len(down.calls) != 1 || len(up.calls) != 1 {
+		t.Errorf("calls: down %v, up %v; want one each", down.calls, up.calls)
+	}
+	if days, _ := api.history.Days(day, day); len(days) != 1 || days[0].Source != "up" {
+		t.Errorf("history = %+v, want the day from up", days)
+	}
+
+	up.err = errors.New("quota exceeded")
+	next := day.AddDate(0, 0, 1)
+	err := api.Backfill(next, next)
+	if err == nil || !errors.Is(err, down.err) || !errors.Is(err, up.err) {
+		t.Errorf("Backfill() error = %v, want both provider errors", err)
+	}
+}
+
+func TestDayRangesMerged(t *testing.T) {
+	ranges := dayRanges{
+		{From: "2026-01-10", To: "2026-01-12"},
+		{From: "2026-01-01", To: "2026-01-05"},
+		{From: "2026-01-06", To: "2026-01-07"},
+		{From: "2026-01-03", To: "2026-01-04"},
+	}
+	want := dayRanges{{From: "2026-01-01", To: "2026-01-07"}, {From: "2026-01-10", To: "2026-01-12"}}
+	got := ranges.merged()
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("merged() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,339 @@
+package exchangerate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPProviderConfig defines a JSON provider in the config file, so that a
+// source like exchangerate-api.com can be added without code.
+type HTTPProviderConfig struct {
+	Name string `json:"name"`
+	// URL is the request URL. {base} is replaced with the base currency and
+	// {date} with the requested day (today for the latest rates); ${VAR}
+	// expands an environment variable.
+	URL string `json:"url"`
+	// Headers are sent with every request, with ${VAR} expanded.
+	Headers map[string]string `json:"headers"`
+	// Rates, Base and Date are dot paths into the response, e.g.
+	// "data.rates" or "$.items.0.base". Base and Date are optional.
+	Rates string `json:"rates"`
+	Base  string `json:"base"`
+	Date  string `json:"date"`
+	// Bid and Ask are optional paths to the buy and sell quotes, in the same
+	// form as Rates. They are set together or not at all.
+	Bid string `json:"bid"`
+	Ask string `json:"ask"`
+	// Success is an optional predicate on the response, either a path that
+	// must be true ("success") or path=value ("result=success").
+	Success string `json:"success"`
+}
+
+// httpProvider fetches rates as described by an HTTPProviderConfig.
+type httpProvider struct {
+	config HTTPProviderConfig
+	client *http.Client
+}
+
+// NewHTTPProvider returns a provider for config that sends its requests
+// with client, or http.DefaultClient if nil. If the URL contains {date},
+// the provider also serves historical series, one request per day.
+func NewHTTPProvider(config HTTPProviderConfig, client *http.Client) (Provider, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("provider has no name")
+	}
+	if config.URL == "" || config.Rates == "" {
+		return nil, fmt.Errorf("provider %s: url and rates are required", config.Name)
+	}
+	if !strings.Contains(config.URL, "{base}") {
+		return nil, fmt.Errorf("provider %s: url must contain {base}", config.Name)
+	}
+	if (config.Bid == "") != (config.Ask == "") {
+		return nil, fmt.Errorf("provider %s: bid and ask must be set together", config.Name)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	p := &httpProvider{config: config, client: client}
+	if strings.Contains(config.URL, "{date}") {
+		return &httpSeriesProvider{p}, nil
+	}
+	return p, nil
+}
+
+func (p *httpProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *httpProvider) Fetch(baseCurrency string) (map[string]float64, *RateInfo, error) {
+	day, err := p.fetchDay(baseCurrency, time.Now().UTC())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return day.rates, &RateInfo{
+		Source:        p.config.Name,
+		Timestamp:     time.Now(),
+		ExpiresAt:     time.Now().Add(24 * time.Hour),
+		EffectiveDate: day.date,
+		PayloadDigest: payloadDigest(day.body),
+		Quota:         quotaHeaders(day.header),
+		Bid:           day.bid,
+		Ask:           day.ask,
+	}, nil
+}
+
+// httpDay is the content of one response.
+type httpDay struct {
+	rates    map[string]float64
+	bid, ask map[string]float64
+	date     string
+	header   http.Header
+	body     []byte
+}
+
+// fetchDay requests the rates for day and extracts them from the response.
+func (p *httpProvider) fetchDay(baseCurrency string, day time.Time) (*httpDay, error) {
+	var secrets []string
+	expand := func(s string) string {
+		return os.Expand(s, func(name string) string {
+			value := os.Getenv(name)
+			if value != "" {
+				secrets = append(secrets, value)
+			}
+			return value
+		})
+	}
+
+	url := strings.NewReplacer("{base}", baseCurrency, "{date}", day.Format(dateLayout)).Replace(expand(p.config.URL))
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, redactAll(err, secrets)
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, value := range p.config.Headers {
+		req.Header.Set(name, expand(value))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, redactAll(err, secrets)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, redactAll(err, secrets)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode)
+	}
+
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+
+	if err := p.checkSuccess(doc); err != nil {
+		return nil, err
+	}
+
+	rates, err := extractRates(doc, p.config.Rates)
+	if err != nil {
+		return nil, err
+	}
+
+	var reportedBase string
+	if p.config.Base != "" {
+		value, err := lookupPath(doc, p.config.Base)
+		if err != nil {
+			return nil, err
+		}
+		reportedBase = fmt.Sprint(value)
+	}
+
+	if err := validatePayload(baseCurrency, reportedBase, rates); err != nil {
+		return nil, err
+	}
+	rates[baseCurrency] = 1.0
+
+	result := &httpDay{rates: rates, header: resp.Header, body: body}
+	if p.config.Bid != "" {
+		if result.bid, err = extractRates(doc, p.config.Bid); err != nil {
+			return nil, err
+		}
+		if result.ask, err = extractRates(doc, p.config.Ask); err != nil {
+			return nil, err
+		}
+		if err := validateQuotes(result.bid, result.ask); err != nil {
+			return nil, err
+		}
+		result.bid[baseCurrency], result.ask[baseCurrency] = 1.0, 1.0
+	}
+
+	if p.config.Date != "" {
+		value, err := lookupPath(doc, p.config.Date)
+		if err != nil {
+			return nil, err
+		}
+		result.date = formatDate(value)
+	}
+
+	return result, nil
+}
+
+// checkSuccess applies the Success predicate to the decoded response.
+func (p *httpProvider) checkSuccess(doc any) error {
+	if p.config.Success == "" {
+		return nil
+	}
+
+	path, want, hasValue := strings.Cut(p.config.Success, "=")
+	value, err := lookupPath(doc, path)
+	if err != nil {
+		return err
+	}
+
+	if hasValue {
+		if got := fmt.Sprint(value); got != want {
+			return fmt.Errorf("API returned %s=%s", path, got)
+		}
+		return nil
+	}
+	if value != true {
+		return fmt.Errorf("API returned %s=%v", path, value)
+	}
+	return nil
+}
+
+type httpSeriesProvider struct {
+	*httpProvider
+}
+
+// FetchSeries requests each day in [from, to] in turn. Days the endpoint
+// has no rates for, such as weekends, are skipped.
+func (p *httpSeriesProvider) FetchSeries(baseCurrency string, from, to time.Time) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		result, err := p.fetchDay(baseCurrency, day)
+		if err != nil {
+			if errors.Is(err, statusError(http.StatusNotFound)) {
+				continue
+			}
+			return nil, fmt.Errorf("%s: %w", day.Format(dateLayout), err)
+		}
+		date := result.date
+		if date == "" {
+			date = day.Format(dateLayout)
+		}
+		entries = append(entries, HistoryEntry{
+			Date:      date,
+			Base:      baseCurrency,
+			Rates:     result.rates,
+			Source:    p.config.Name,
+			FetchedAt: time.Now(),
+		})
+	}
+	return entries, nil
+}
+
+// lookupPath walks a dot path such as "data.rates" or "$.items.0.base"
+// through a decoded JSON document. Numeric segments index arrays.
+func lookupPath(doc any, path string) (any, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return doc, nil
+	}
+
+	value := doc
+	for _, segment := range strings.Split(path, ".") {
+		switch node := value.(type) {
+		case map[string]any:
+			next, ok := node[segment]
+			if !ok {
+				return nil, fmt.Errorf("%w: response has no %s", ErrInvalidRates, path)
+			}
+			value = next
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("%w: response has no %s", ErrInvalidRates, path)
+			}
+			value = node[i]
+		default:
+			return nil, fmt.Errorf("%w: response has no %s", ErrInvalidRates, path)
+		}
+	}
+	return value, nil
+}
+
+// extractRates reads the rates object at path. Rates may be numbers or
+// numeric strings.
+func extractRates(doc any, path string) (map[string]float64, error) {
+	value, err := lookupPath(doc, path)
+	if err != nil {
+		return nil, err
+	}
+
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not an object", ErrInvalidRates, path)
+	}
+
+	rates := make(map[string]float64, len(object))
+	for currency, v := range object {
+		switch rate := v.(type) {
+		case float64:
+			rates[currency] = rate
+		case string:
+			parsed, err := strconv.ParseFloat(rate, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s rate is %q", ErrInvalidRates, currency, rate)
+			}
+			rates[currency] = parsed
+		default:
+			return nil, fmt.Errorf("%w: %s rate is %v", ErrInvalidRates, currency, v)
+		}
+	}
+	return rates, nil
+}
+
+// formatDate turns a date field into YYYY-MM-DD. Unix timestamps and
+// RFC 3339 times are converted; other strings are kept as they are.
+func formatDate(value any) string {
+	switch v := value.(type) {
+	case float64:
+		return time.Unix(int64(v), 0).UTC().Format(dateLayout)
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t.UTC().Format(dateLayout)
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// statusError is a non-200 response status.
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("API returned status %d", int(e))
+}
+
+func redactAll(err error, secrets []string) error {
+	for _, secret := range secrets {
+		err = redact(err, secret)
+	}
+	return err
+}
@@ -0,0 +1,256 @@
+package exchangerate
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPProviderFetch(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		config   HTTPProviderConfig
+		want     map[string]float64
+		wantDate string
+		wantErr  bool
+	}{
+		{
+			name:     "nested rates and success flag",
+			response: `{"success": true, "data": {"base": "EUR", "rates": {"PLN": 4.25, "USD": 1.1}, "date": "2026-10-01"}}`,
+			config:   HTTPProviderConfig{Rates: "data.rates", Base: "data.base", Date: "data.date", Success: "success"},
+			want:     map[string]float64{"EUR": 1, "PLN": 4.25, "USD": 1.1},
+			wantDate: "2026-10-01",
+		},
+		{
+			name:     "array index, numeric strings and success value",
+			response: `{"result": "ok", "items": [{"base": "EUR", "rates": {"PLN": "4.25", "USD": "1.1"}, "ts": 1790000000}]}`,
+			config:   HTTPProviderConfig{Rates: "$.items.0.rates", Base: "$.items.0.base", Date: "items.0.ts", Success: "result=ok"},
+			want:     map[string]float64{"EUR": 1, "PLN": 4.25, "USD": 1.1},
+			wantDate: time.Unix(1790000000, 0).UTC().Format(dateLayout),
+		},
+		{
+			name:     "success flag false",
+			response: `{"success": false, "rates": {"PLN": 4.25}}`,
+			config:   HTTPProviderConfig{Rates: "rates", Success: "success"},
+			wantErr:  true,
+		},
+		{
+			name:     "success value mismatch",
+			response: `{"result": "error", "rates": {"PLN": 4.25}}`,
+			config:   HTTPProviderConfig{Rates: "rates", Success: "result=ok"},
+			wantErr:  true,
+		},
+		{
+			name:     "wrong base",
+			response: `{"base": "USD", "rates": {"PLN": 3.9}}`,
+			config:   HTTPProviderConfig{Rates: "rates", Base: "base"},
+			wantErr:  true,
+		},
+		{
+			name:     "array index out of range",
+			response: `{"items": []}`,
+			config:   HTTPProviderConfig{Rates: "items.0.rates"},
+			wantErr:  true,
+		},
+		{
+			name:     "rate that is not a number",
+			response: `{"rates": {"PLN": "n/a"}}`,
+			config:   HTTPProviderConfig{Rates: "rates"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				fmt.Fprint(w, tt.response)
+			}))
+			defer server.Close()
+
+			tt.config.Name = "test"
+			tt.config.URL = server.URL + "/latest/{base}"
+			p, err := NewHTTPProvider(tt.config, server.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rates, info, err := p.Fetch("EUR")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Fetch() = %v, want an error", rates)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotPath != "/latest/EUR" {
+				t.Errorf("requested %s, want /latest/EUR", gotPath)
+			}
+			if fmt.Sprint(rates) != fmt.Sprint(tt.want) {
+				t.Errorf("rates = %v, want %v", rates, tt.want)
+			}
+			if info.EffectiveDate != tt.wantDate {
+				t.Errorf("effective date = %q, want %q", info.EffectiveDate, tt.wantDate)
+			}
+			if info.Source != "test" || info.PayloadDigest == "" {
+				t.Errorf("info = %+v, want source and payload digest", info)
+			}
+		})
+	}
+}
+
+func TestHTTPProviderSubstitutesURLAndHeaders(t *testing.T) {
+	t.Setenv("S_CALC_TEST_TOKEN", "s3cret")
+
+	var gotQuery, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		gotAuth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"rates": {"PLN": 4.25}}`)
+	}))
+	defer server.Close()
+
+	p, err := NewHTTPProvider(HTTPProviderConfig{
+		Name:    "test",
+		URL:     server.URL + "/rates?base={base}&day={date}&key=${S_CALC_TEST_TOKEN}",
+		Headers: map[string]string{"Authorization": "Bearer ${S_CALC_TEST_TOKEN}"},
+		Rates:   "rates",
+	}, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := p.Fetch("EUR"); err != nil {
+		t.Fatal(err)
+	}
+	today := time.Now().UTC().Format(dateLayout)
+	if want := "base=EUR&day=" + today + "&key=s3cret"; gotQuery != want {
+		t.Errorf("query = %q, want %q", gotQuery, want)
+	}
+	if gotAuth != "Bearer s3cret" {
+		t.Errorf("Authorization = %q, want the expanded token", gotAuth)
+	}
+}
+
+func TestHTTPProviderBidAsk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"mid": {"PLN": 4.25}, "quotes": {"bid": {"PLN": "4.20"}, "ask": {"PLN": 4.30}}}`)
+	}))
+	defer server.Close()
+
+	p, err := NewHTTPProvider(HTTPProviderConfig{
+		Name:  "test",
+		URL:   server.URL + "/{base}",
+		Rates: "mid",
+		Bid:   "quotes.bid",
+		Ask:   "quotes.ask",
+	}, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, info, err := p.Fetch("EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Bid["PLN"] != 4.20 || info.Ask["PLN"] != 4.30 || info.Bid["EUR"] != 1 {
+		t.Errorf("bid %v, ask %v; want PLN 4.20/4.30 and EUR 1", info.Bid, info.Ask)
+	}
+}
+
+func TestHTTPProviderFetchSeriesSkipsMissingDays(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2026-10-03/EUR", "/2026-10-04/EUR":
+			http.NotFound(w, r)
+		default:
+			fmt.Fprint(w, `{"rates": {"PLN": 4.25}}`)
+		}
+	}))
+	defer server.Close()
+
+	p, err := NewHTTPProvider(HTTPProviderConfig{Name: "test", URL: server.URL + "/{date}/{base}", Rates: "rates"}, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	series, ok := p.(SeriesProvider)
+	if !ok {
+		t.Fatal("provider with {date} in its URL does not serve series")
+	}
+
+	from := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+	entries, err := series.FetchSeries("EUR", from, from.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dates []string
+	for _, entry := range entries {
+		dates = append(dates, entry.Date)
+	}
+	if got := strings.Join(dates, ","); got != "2026-10-02,2026-10-05" {
+		t.Errorf("dates = %s, want the 404 days skipped", got)
+	}
+}
+
+func TestHTTPProviderFetchSeriesFailsOnOtherErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	p, _ := NewHTTPProvider(HTTPProviderConfig{Name: "test", URL: server.URL + "/{date}/{base}", Rates: "rates"}, server.Client())
+	day := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+	if _, err := p.(SeriesProvider).FetchSeries("EUR", day, day); !errors.Is(err, statusError(http.StatusServiceUnavailable)) {
+		t.Errorf("FetchSeries() error = %v, want status 503", err)
+	}
+}
+
+func TestHTTPProviderRedactsSecrets(t *testing.T) {
+	t.Setenv("S_CALC_TEST_TOKEN", "s3cret")
+
+	// A closed server makes client.Do fail with the request URL in the error.
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	p, err := NewHTTPProvider(HTTPProviderConfig{
+		Name:  "test",
+		URL:   server.URL + "/{base}?key=${S_CALC_TEST_TOKEN}",
+		Rates: "rates",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = p.Fetch("EUR")
+	if err == nil {
+		t.Fatal("Fetch() from a closed server succeeded")
+	}
+	if strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("error leaks the secret: %v", err)
+	}
+	if !strings.Contains(err.Error(), "key=****") {
+		t.Errorf("error = %v, want the secret replaced with ****", err)
+	}
+}
+
+func TestNewHTTPProviderValidatesConfig(t *testing.T) {
+	for _, config := range []HTTPProviderConfig{
+		{URL: "http://example.com/{base}", Rates: "rates"},
+		{Name: "test", Rates: "rates"},
+		{Name: "test", URL: "http://example.com/{base}"},
+		{Name: "test", URL: "http://example.com/latest", Rates: "rates"},
+		{Name: "test", URL: "http://example.com/{base}", Rates: "rates", Bid: "bid"},
+	} {
+		if _, err := NewHTTPProvider(config, nil); err == nil {
+			t.Errorf("NewHTTPProvider(%+v) accepted an invalid config", config)
+		}
+	}
+}
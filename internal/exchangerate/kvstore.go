@@ -0,0 +1,259 @@
+package exchangerate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	kvFileName = "s-calc.db"
+	// kvCompactMin is the number of records below which the log is never
+	// compacted; above it, the log is rewritten once it holds more than
+	// kvCompactRatio times as many records as live keys.
+	kvCompactMin   = 1000
+	kvCompactRatio = 2
+	// kvLockWait bounds how long a write waits for a compaction to finish;
+	// kvLockMaxAge is when a lock left by a crashed process is taken over.
+	kvLockWait   = 5 * time.Second
+	kvLockMaxAge = time.Minute
+)
+
+// KVStore is a Store kept in a single append-only file of JSON lines, one
+// record per write. The latest record for a key wins; the log is compacted
+// when it grows well beyond the live keys. Writes by other processes are
+// picked up on the next access. Writes and compaction take the same lock
+// file, so that no write goes to a log that compaction is replacing.
+type KVStore struct {
+	mu      sync.Mutex
+	path    string
+	index   map[string]json.RawMessage
+	records int
+	offset  int64
+}
+
+type kvRecord struct {
+	Key     string          `json:"k"`
+	Value   json.RawMessage `json:"v,omitempty"`
+	Deleted bool            `json:"d,omitempty"`
+}
+
+// OpenKVStore opens or creates the store file at path.
+func OpenKVStore(path string) (*KVStore, error) {
+	s := &KVStore{path: path}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	if s.records > kvCompactMin && s.records > kvCompactRatio*len(s.index) {
+		if err := s.compact(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *KVStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.sync(); err != nil {
+		return nil, err
+	}
+	value, ok := s.index[key]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func (s *KVStore) Put(key string, value []byte) error {
+	if !json.Valid(value) {
+		return fmt.Errorf("kv store: value for %s is not JSON", key)
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, value); err != nil {
+		return err
+	}
+	return s.append(kvRecord{Key: key, Value: compact.Bytes()})
+}
+
+func (s *KVStore) Delete(key string) error {
+	return s.append(kvRecord{Key: key, Deleted: true})
+}
+
+func (s *KVStore) Keys(prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.sync(); err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for key := range s.index {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *KVStore) append(record kvRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if err := s.lock(); err != nil {
+		return err
+	}
+	defer s.unlock()
+
+	if err := s.sync(); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open kv store: %w", err)
+	}
+	// A single write keeps the line whole when several processes append.
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write kv store: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write kv store: %w", err)
+	}
+
+	return s.sync()
+}
+
+// sync applies records appended since the last read, by this or another
+// process, and reloads the file if it was compacted in the meantime.
+func (s *KVStore) sync() error {
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		s.index, s.records, s.offset = map[string]json.RawMessage{}, 0, 0
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read kv store: %w", err)
+	}
+
+	switch {
+	case info.Size() < s.offset:
+		return s.reload()
+	case info.Size() > s.offset:
+		return s.readFrom(s.offset)
+	}
+	return nil
+}
+
+func (s *KVStore) reload() error {
+	s.index, s.records, s.offset = map[string]json.RawMessage{}, 0, 0
+	return s.readFrom(0)
+}
+
+func (s *KVStore) readFrom(offset int64) error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read kv store: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read kv store: %w", err)
+	}
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// Leave a partial last line for the next sync, once its writer is done.
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read kv store: %w", err)
+		}
+		s.offset += int64(len(line))
+
+		var record kvRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			continue
+		}
+		s.records++
+		if record.Deleted {
+			delete(s.index, record.Key)
+		} else {
+			s.index[record.Key] = record.Value
+		}
+	}
+}
+
+// lock waits for the lock shared by writers and compaction.
+func (s *KVStore) lock() error {
+	deadline := time.Now().Add(kvLockWait)
+	for !lockFile(s.path+".lock", kvLockMaxAge) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("failed to write kv store: %s.lock is held by another process", s.path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil
+}
+
+func (s *KVStore) unlock() {
+	os.Remove(s.path + ".lock")
+}
+
+// compact rewrites the log with one record per live key. It is skipped while
+// another process is writing or compacting.
+func (s *KVStore) compact() error {
+	if !lockFile(s.path+".lock", kvLockMaxAge) {
+		return nil
+	}
+	defer s.unlock()
+
+	if err := s.sync(); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(s.index))
+	for key := range s.index {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		line, err := json.Marshal(kvRecord{Key: key, Value: s.index[key]})
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	if err := writeFileAtomic(s.path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to compact kv store: %w", err)
+	}
+	return s.reload()
+}
@@ -0,0 +1,85 @@
+package exchangerate
+
+import (
+	"sync"
+	"time"
+)
+
+// ProviderStats counts the fetches made from one provider.
+type ProviderStats struct {
+	Requests    uint64
+	Errors      uint64
+	LatencySum  time.Duration
+	LastLatency time.Duration
+}
+
+// Metrics counts cache lookups and provider fetches made by this process.
+type Metrics struct {
+	CacheHits   uint64
+	CacheMisses uint64
+	Providers   map[string]ProviderStats
+}
+
+type metrics struct {
+	mu sync.Mutex
+	Metrics
+}
+
+// Metrics returns a copy of the counters collected since the API was created.
+func (api *ExchangeRateAPI) Metrics() Metrics {
+	api.metrics.mu.Lock()
+	defer api.metrics.mu.Unlock()
+
+	m := api.metrics.Metrics
+	m.Providers = make(map[string]ProviderStats, len(api.metrics.Providers))
+	for name, stats := range api.metrics.Providers {
+		m.Providers[name] = stats
+	}
+	return m
+}
+
+func (m *metrics) cacheLookup(hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if hit {
+		m.CacheHits++
+	} else {
+		m.CacheMisses++
+	}
+}
+
+func (m *metrics) providerFetch(name string, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Providers == nil {
+		m.Providers = make(map[string]ProviderStats)
+	}
+	stats := m.Providers[name]
+	stats.Requests++
+	if err != nil {
+		stats.Errors++
+	}
+	stats.LatencySum += latency
+	stats.LastLatency = latency
+	m.Providers[name] = stats
+}
+
+// fetchFrom fetches and validates the canonical rates from p, recording the
+// latency and outcome.
+func (api *ExchangeRateAPI) fetchFrom(p Provider, base string, previous *CacheData) (map[string]float64, *RateInfo, error) {
+	start := time.Now()
+	rates, info, err := p.Fetch(base)
+	latency := time.Since(start)
+	if err == nil {
+		err = api.validateRates(rates, previous)
+	}
+	api.metrics.providerFetch(p.Name(), latency, err)
+	if err != nil {
+		api.logger.Info("provider failed", "provider", p.Name(), "base", base, "duration", latency, "error", err)
+	} else {
+		api.logger.Debug("provider fetched", "provider", p.Name(), "base", base, "duration", latency, "rates", len(rates))
+	}
+	return rates, info, err
+}
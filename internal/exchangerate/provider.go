@@ -0,0 +1,37 @@
+package exchangerate
+
+import "time"
+
+// Provider fetches the latest rates quoted against a base currency.
+type Provider interface {
+	Name() string
+	Fetch(baseCurrency string) (map[string]float64, *RateInfo, error)
+}
+
+// SeriesProvider is a Provider that can also return historical daily rates.
+type SeriesProvider interface {
+	Provider
+	FetchSeries(baseCurrency string, from, to time.Time) ([]HistoryEntry, error)
+}
+
+type provider struct {
+	name  string
+	fetch func(baseCurrency string) (map[string]float64, *RateInfo, error)
+}
+
+func (p *provider) Name() string {
+	return p.name
+}
+
+func (p *provider) Fetch(baseCurrency string) (map[string]float64, *RateInfo, error) {
+	return p.fetch(baseCurrency)
+}
+
+type seriesProvider struct {
+	provider
+	series func(baseCurrency string, from, to time.Time) ([]HistoryEntry, error)
+}
+
+func (p *seriesProvider) FetchSeries(baseCurrency string, from, to time.Time) ([]HistoryEntry, error) {
+	return p.series(baseCurrency, from, to)
+}
@@ -0,0 +1,114 @@
+package exchangerate
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// RateKind tells whether a rate was quoted by a provider or derived from other quotes.
+type RateKind string
+
+const (
+	RateDirect  RateKind = "direct"
+	RateDerived RateKind = "derived"
+	RateManual  RateKind = "manual"
+)
+
+const defaultCanonicalBase = "EUR"
+
+// RateTable is the canonical rate set, quoted against a single base currency.
+// Bid and Ask are optional and hold the quotes for selling and buying Base.
+type RateTable struct {
+	Base  string
+	Rates map[string]float64
+	Bid   map[string]float64
+	Ask   map[string]float64
+}
+
+// Rebase returns the rates quoted against base, triangulating through the
+// table's own base when they differ.
+func (t *RateTable) Rebase(base string) (map[string]float64, map[string]RateKind, error) {
+	rates := make(map[string]float64, len(t.Rates))
+	kinds := make(map[string]RateKind, len(t.Rates))
+
+	if base == t.Base {
+		for currency, rate := range t.Rates {
+			rates[currency] = rate
+			kinds[currency] = RateDirect
+		}
+		rates[base] = 1.0
+		kinds[base] = RateDirect
+		return rates, kinds, nil
+	}
+
+	baseRate, ok := t.Rates[base]
+	if !ok || baseRate <= 0 {
+		return nil, nil, fmt.Errorf("no %s rate in %s rate table", base, t.Base)
+	}
+
+	// EUR->PLN = 4.25, EUR->USD = 1.10, so PLN->USD = 1.10 / 4.25
+	for currency, rate := range t.Rates {
+		rates[currency] = rate / baseRate
+		kinds[currency] = RateDerived
+	}
+	rates[t.Base] = 1.0 / baseRate
+	kinds[t.Base] = RateDerived
+	rates[base] = 1.0
+	kinds[base] = RateDirect
+
+	return rates, kinds, nil
+}
+
+// RebaseBidAsk returns the bid and ask quotes against base. Selling base for
+// X goes through the table's base: sell base at its ask, then buy X at its bid.
+func (t *RateTable) RebaseBidAsk(base string) (map[string]float64, map[string]float64) {
+	if len(t.Bid) == 0 || len(t.Ask) == 0 {
+		return nil, nil
+	}
+
+	baseBid, baseAsk := 1.0, 1.0
+	if base != t.Base {
+		var okBid, okAsk bool
+		baseBid, okBid = t.Bid[base]
+		baseAsk, okAsk = t.Ask[base]
+		if !okBid || !okAsk || baseBid <= 0 || baseAsk <= 0 {
+			return nil, nil
+		}
+	}
+
+	bid := make(map[string]float64, len(t.Bid))
+	ask := make(map[string]float64, len(t.Ask))
+	for currency, rate := range t.Bid {
+		bid[currency] = rate / baseAsk
+	}
+	for currency, rate := range t.Ask {
+		ask[currency] = rate / baseBid
+	}
+	return bid, ask
+}
+
+// validateQuotes checks that every bid and ask quote is positive and that
+// no bid is above its ask.
+func validateQuotes(bid, ask map[string]float64) error {
+	for currency, b := range bid {
+		a, ok := ask[currency]
+		if !ok || b <= 0 || a <= 0 || b > a || math.IsInf(a, 0) {
+			return fmt.Errorf("%w: invalid %s bid/ask %v/%v", ErrInvalidRates, currency, b, a)
+		}
+	}
+	for currency := range ask {
+		if _, ok := bid[currency]; !ok {
+			return fmt.Errorf("%w: %s has an ask but no bid", ErrInvalidRates, currency)
+		}
+	}
+	return nil
+}
+
+func getCanonicalBase() string {
+	if base := os.Getenv("S_CALC_BASE"); base != "" {
+		return strings.ToUpper(base)
+	}
+	return defaultCanonicalBase
+}
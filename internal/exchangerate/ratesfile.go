@@ -0,0 +1,325 @@
+package exchangerate
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RatesFile is a rate set read from or exported to a file.
+type RatesFile struct {
+	Base   string             `json:"base"`
+	Date   string             `json:"date,omitempty"`
+	Source string             `json:"source,omitempty"`
+	Rates  map[string]float64 `json:"rates"`
+	// Bid and Ask are optional buy and sell quotes against Base.
+	Bid map[string]float64 `json:"bid,omitempty"`
+	Ask map[string]float64 `json:"ask,omitempty"`
+}
+
+// Rates file formats.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatXML  = "xml"
+)
+
+// FileFormat returns the format implied by a file name's extension, or ""
+// if the extension is not a known format.
+func FileFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".csv":
+		return FormatCSV
+	case ".xml":
+		return FormatXML
+	}
+	return ""
+}
+
+// ParseRatesFile decodes data in format, or in the format its content looks
+// like if format is "":
+//
+//   - json: {"base": "EUR", "date": "2026-10-16", "rates": {"PLN": 4.25}}
+//   - csv: a header with currency and rate columns, and optional base,
+//     date, bid and ask columns
+//   - xml: the ECB euro reference rates (eurofxref-daily.xml), quoted
+//     against EUR
+func ParseRatesFile(data []byte, format string) (*RatesFile, error) {
+	if format == "" {
+		format = sniffFormat(data)
+	}
+
+	var file *RatesFile
+	var err error
+	switch format {
+	case FormatJSON:
+		file = &RatesFile{}
+		err = json.Unmarshal(data, file)
+	case FormatCSV:
+		file, err = parseRatesCSV(data)
+	case FormatXML:
+		file, err = parseRatesECB(data)
+	default:
+		return nil, fmt.Errorf("unknown rates file format: %s (valid: json, csv, xml)", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s rates: %w", format, err)
+	}
+
+	file.Base = strings.ToUpper(file.Base)
+	if file.Base == "" {
+		return nil, fmt.Errorf("%w: rates file has no base currency", ErrInvalidRates)
+	}
+	return file, nil
+}
+
+func sniffFormat(data []byte) string {
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatJSON
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return FormatXML
+	default:
+		return FormatCSV
+	}
+}
+
+func parseRatesCSV(data []byte) (*RatesFile, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("expected a header and at least one rate")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	currencyCol, okCurrency := columns["currency"]
+	rateCol, okRate := columns["rate"]
+	if !okCurrency || !okRate {
+		return nil, fmt.Errorf("header must have currency and rate columns")
+	}
+	baseCol, okBase := columns["base"]
+	dateCol, okDate := columns["date"]
+	bidCol, okBid := columns["bid"]
+	askCol, okAsk := columns["ask"]
+	if okBid != okAsk {
+		return nil, fmt.Errorf("header must have both bid and ask columns or neither")
+	}
+
+	file := &RatesFile{Rates: make(map[string]float64)}
+	for line, record := range records[1:] {
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[rateCol]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate %q", line+2, record[rateCol])
+		}
+		currency := strings.ToUpper(strings.TrimSpace(record[currencyCol]))
+		file.Rates[currency] = rate
+
+		// Bid and ask cells may be left empty for currencies without quotes.
+		if okBid && (strings.TrimSpace(record[bidCol]) != "" || strings.TrimSpace(record[askCol]) != "") {
+			bid, errBid := strconv.ParseFloat(strings.TrimSpace(record[bidCol]), 64)
+			ask, errAsk := strconv.ParseFloat(strings.TrimSpace(record[askCol]), 64)
+			if errBid != nil || errAsk != nil {
+				return nil, fmt.Errorf("line %d: invalid bid/ask %q/%q", line+2, record[bidCol], record[askCol])
+			}
+			if file.Bid == nil {
+				file.Bid, file.Ask = make(map[string]float64), make(map[string]float64)
+			}
+			file.Bid[currency], file.Ask[currency] = bid, ask
+		}
+
+		if okBase {
+			base := strings.TrimSpace(record[baseCol])
+			if file.Base != "" && !strings.EqualFold(base, file.Base) {
+				return nil, fmt.Errorf("line %d: base %s differs from %s", line+2, base, file.Base)
+			}
+			file.Base = base
+		}
+		if okDate && file.Date == "" {
+			file.Date = strings.TrimSpace(record[dateCol])
+		}
+	}
+	return file, nil
+}
+
+// ecbEnvelope is the layout of the ECB reference rate files. Only the first
+// (most recent) day is used.
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string  `xml:"currency,attr"`
+			Rate     float64 `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+func parseRatesECB(data []byte) (*RatesFile, error) {
+	var envelope ecbEnvelope
+	if err := xml.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+	if len(envelope.Days) == 0 {
+		return nil, fmt.Errorf("no rates found")
+	}
+
+	day := envelope.Days[0]
+	file := &RatesFile{Base: "EUR", Date: day.Time, Rates: make(map[string]float64)}
+	for _, rate := range day.Rates {
+		file.Rates[rate.Currency] = rate.Rate
+	}
+	return file, nil
+}
+
+// WriteRatesFile encodes file in format. The xml format follows the ECB
+// layout and therefore requires EUR as the base.
+func WriteRatesFile(w io.Writer, file *RatesFile, format string) error {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(file, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		header := []string{"base", "currency", "rate", "date"}
+		if len(file.Bid) > 0 {
+			header = append(header, "bid", "ask")
+		}
+		_ = cw.Write(header)
+		for _, currency := range sortedKeys(file.Rates) {
+			record := []string{file.Base, currency, strconv.FormatFloat(file.Rates[currency], 'f', -1, 64), file.Date}
+			if len(file.Bid) > 0 {
+				record = append(record, formatQuote(file.Bid, currency), formatQuote(file.Ask, currency))
+			}
+			_ = cw.Write(record)
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatXML:
+		if file.Base != "EUR" {
+			return fmt.Errorf("the xml format requires EUR as the base, got %s", file.Base)
+		}
+		var sb strings.Builder
+		sb.WriteString(xml.Header)
+		sb.WriteString(`<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">` + "\n")
+		sb.WriteString("\t<Cube>\n")
+		fmt.Fprintf(&sb, "\t\t<Cube time=\"%s\">\n", file.Date)
+		for _, currency := range sortedKeys(file.Rates) {
+			if currency == file.Base {
+				continue
+			}
+			fmt.Fprintf(&sb, "\t\t\t<Cube currency=\"%s\" rate=\"%s\"/>\n", currency, strconv.FormatFloat(file.Rates[currency], 'f', -1, 64))
+		}
+		sb.WriteString("\t\t</Cube>\n\t</Cube>\n</gesmes:Envelope>\n")
+		_, err := io.WriteString(w, sb.String())
+		return err
+	default:
+		return fmt.Errorf("unknown rates file format: %s (valid: json, csv, xml)", format)
+	}
+}
+
+// formatQuote formats the quote for currency, or "" if there is none.
+func formatQuote(quotes map[string]float64, currency string) string {
+	quote, ok := quotes[currency]
+	if !ok {
+		return ""
+	}
+	return strconv.FormatFloat(quote, 'f', -1, 64)
+}
+
+// fileProvider reads rates from a file, or from stdin when the path is "-".
+type fileProvider struct {
+	path string
+	data []byte
+}
+
+// NewFileProvider returns a provider that serves the rates in the file at
+// path, or on stdin if path is "-". Rates quoted against another base than
+// the requested one are triangulated.
+func NewFileProvider(path string) Provider {
+	return &fileProvider{path: path}
+}
+
+func (p *fileProvider) Name() string {
+	if p.path == "-" {
+		return "stdin"
+	}
+	return "file:" + p.path
+}
+
+func (p *fileProvider) Fetch(baseCurrency string) (map[string]float64, *RateInfo, error) {
+	if p.data == nil {
+		// Stdin can only be read once, so the content is kept for later calls.
+		var err error
+		if p.path == "-" {
+			p.data, err = io.ReadAll(os.Stdin)
+		} else {
+			p.data, err = os.ReadFile(p.path)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read rates file: %w", err)
+		}
+	}
+
+	file, err := ParseRatesFile(p.data, FileFormat(p.path))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := validatePayload(file.Base, file.Base, file.Rates); err != nil {
+		return nil, nil, err
+	}
+	file.Rates[file.Base] = 1.0
+
+	table := &RateTable{Base: file.Base, Rates: file.Rates}
+	if len(file.Bid) > 0 && len(file.Ask) > 0 {
+		if err := validateQuotes(file.Bid, file.Ask); err != nil {
+			return nil, nil, err
+		}
+		file.Bid[file.Base], file.Ask[file.Base] = 1.0, 1.0
+		table.Bid, table.Ask = file.Bid, file.Ask
+	}
+	rates, kinds, err := table.Rebase(baseCurrency)
+	if err != nil {
+		return nil, nil, err
+	}
+	bid, ask := table.RebaseBidAsk(baseCurrency)
+
+	source := p.Name()
+	if file.Source != "" {
+		source = fmt.Sprintf("%s (%s)", source, file.Source)
+	}
+
+	// The rates are as old as the file says, however recently it was read.
+	timestamp := time.Now()
+	if date, err := time.Parse(dateLayout, file.Date); err == nil {
+		timestamp = date
+	}
+
+	return rates, &RateInfo{
+		Source:        source,
+		Timestamp:     timestamp,
+		Kinds:         kinds,
+		EffectiveDate: file.Date,
+		PayloadDigest: payloadDigest(p.data),
+		Bid:           bid,
+		Ask:           ask,
+	}, nil
+}
@@ -0,0 +1,149 @@
+package exchangerate
+
+import (
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"salary-calc/internal/converter"
+)
+
+func newTestAPI() *ExchangeRateAPI {
+	cache := newTestCache()
+	return &ExchangeRateAPI{
+		cache:          cache,
+		history:        newCacheHistory(cache),
+		base:           "EUR",
+		maxDailyChange: defaultMaxDailyChange,
+		logger:         slog.New(slog.DiscardHandler),
+	}
+}
+
+func TestParseRatesCSVBidAsk(t *testing.T) {
+	file, err := ParseRatesFile([]byte(`base,currency,rate,bid,ask
+EUR,PLN,4.25,4.20,4.30
+EUR,USD,1.1,,
+`), FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Bid["PLN"] != 4.20 || file.Ask["PLN"] != 4.30 {
+		t.Errorf("PLN bid/ask = %v/%v, want 4.20/4.30", file.Bid["PLN"], file.Ask["PLN"])
+	}
+	if _, ok := file.Bid["USD"]; ok {
+		t.Error("empty USD bid cell parsed as a quote")
+	}
+
+	for _, csv := range []string{
+		"base,currency,rate,bid\nEUR,PLN,4.25,4.20\n",
+		"base,currency,rate,bid,ask\nEUR,PLN,4.25,x,4.30\n",
+	} {
+		if _, err := ParseRatesFile([]byte(csv), FormatCSV); err == nil {
+			t.Errorf("ParseRatesFile(%q) accepted invalid bid/ask", csv)
+		}
+	}
+}
+
+func TestFileProviderBidAskReachConvertReceived(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.csv")
+	data := "base,currency,rate,bid,ask\nEUR,PLN,4.25,4.20,4.30\nEUR,USD,1.1,1.09,1.11\nEUR,GBP,0.85,,\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		base     converter.Currency
+		to       converter.Currency
+		mid, bid float64
+	}{
+		// Selling 1000 EUR for PLN gets the PLN bid.
+		{"table base", converter.CurrencyEUR, converter.CurrencyPLN, 4250, 4200},
+		// Selling 1000 PLN for EUR buys EUR at the PLN ask.
+		{"rebased", converter.CurrencyPLN, converter.CurrencyEUR, 1000 / 4.25, 1000 / 4.30},
+		// PLN -> EUR at the PLN ask, then EUR -> USD at the USD bid.
+		{"cross", converter.CurrencyPLN, converter.CurrencyUSD, 1000 * 1.1 / 4.25, 1000 * 1.09 / 4.30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, info, err := newTestAPI().GetRatesFrom(NewFileProvider(path), string(tt.base))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			conv := converter.NewConverter(rates, string(tt.base))
+			conv.SetFees(converter.Fees{tt.to: {UseBidAsk: true}}, info.Bid)
+			if missing := conv.MissingBid(); len(missing) != 0 {
+				t.Fatalf("MissingBid() = %v, want none", missing)
+			}
+
+			input := converter.Input{Amount: 1000, Period: converter.PeriodMonth, Currency: tt.base}
+			mid, err := conv.Convert(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			received, err := conv.ConvertReceived(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := mid[converter.PeriodMonth][tt.to]; math.Abs(got-tt.mid) > 1e-9 {
+				t.Errorf("mid %s = %v, want %v", tt.to, got, tt.mid)
+			}
+			if got := received[converter.PeriodMonth][tt.to]; math.Abs(got-tt.bid) > 1e-9 {
+				t.Errorf("received %s = %v, want %v at the bid", tt.to, got, tt.bid)
+			}
+		})
+	}
+}
+
+func TestFileProviderRejectsCrossedQuotes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	data := `{"base": "EUR", "rates": {"PLN": 4.25}, "bid": {"PLN": 4.30}, "ask": {"PLN": 4.20}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := NewFileProvider(path).Fetch("EUR"); err == nil {
+		t.Error("Fetch() accepted a bid above the ask")
+	}
+}
+
+func TestRefreshFromKeepsFileDate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	data := `{"base": "EUR", "date": "2026-09-01", "rates": {"PLN": 4.25, "USD": 1.1, "GBP": 0.85}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	api := newTestAPI()
+	info, err := api.RefreshFrom(NewFileProvider(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	if !info.Timestamp.Equal(date) || !info.Stale {
+		t.Errorf("info timestamp %v, stale %v; want the file date and stale", info.Timestamp, info.Stale)
+	}
+
+	cached, err := api.cache.Load("EUR")
+	if err != nil || cached == nil {
+		t.Fatalf("Load() = %v, %v", cached, err)
+	}
+	if !cached.Timestamp.Equal(date) || !cached.ExpiresAt.Equal(date.Add(api.cache.ttl)) {
+		t.Errorf("cached timestamp %v, expires %v; want the file date plus TTL", cached.Timestamp, cached.ExpiresAt)
+	}
+	if fresh, _ := api.cache.Get("EUR"); fresh != nil {
+		t.Error("month-old imported rates are served as fresh")
+	}
+
+	entries, err := api.history.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Date != "2026-09-01" {
+		t.Errorf("history = %+v, want one entry dated 2026-09-01", entries)
+	}
+}
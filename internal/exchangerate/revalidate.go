@@ -0,0 +1,127 @@
+package exchangerate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxStaleHours = 168
+	refreshLock          = "refresh"
+	// refreshLockTTL bounds how long a crashed refresh can block the next one.
+	refreshLockTTL = 2 * time.Minute
+)
+
+// SetRevalidator enables stale-while-revalidate: once the cache expires,
+// GetRates keeps serving the expired rates for up to S_CALC_MAX_STALE hours
+// and calls revalidate to refresh them in the background. revalidate must not
+// block; at most one refresh is started at a time across processes.
+func (api *ExchangeRateAPI) SetRevalidator(revalidate func() error) {
+	api.revalidate = revalidate
+}
+
+// SetBase changes the canonical base currency rates are fetched against.
+func (api *ExchangeRateAPI) SetBase(base string) {
+	api.base = base
+}
+
+func (api *ExchangeRateAPI) canRevalidate(previous *CacheData) bool {
+	return api.revalidate != nil && api.maxStale > 0 && previous != nil &&
+		time.Since(previous.ExpiresAt) < api.maxStale
+}
+
+// startRevalidate starts a background refresh unless one is already running.
+func (api *ExchangeRateAPI) startRevalidate() {
+	if !api.cache.lock(refreshLock, refreshLockTTL) {
+		return
+	}
+	if err := api.revalidate(); err != nil {
+		api.cache.unlock(refreshLock)
+	}
+}
+
+// ReleaseRefreshLock ends a background refresh started by
+// stale-while-revalidate. Only the refresh process that was handed the lock
+// may call it.
+func (api *ExchangeRateAPI) ReleaseRefreshLock() {
+	api.cache.unlock(refreshLock)
+}
+
+// Prefetch refreshes the canonical rates unless the cached copy stays fresh
+// for at least margin. It reports whether a refresh happened.
+func (api *ExchangeRateAPI) Prefetch(margin time.Duration) (*RateInfo, bool, error) {
+	if cached, err := api.cache.Get(api.base); err == nil && cached != nil &&
+		cached.ExpiresAt.After(time.Now().Add(margin)) {
+		_, info := fromCache(cached)
+		return info, false, nil
+	}
+
+	info, err := api.Refresh("")
+	if err != nil {
+		return nil, false, err
+	}
+	return info, true, nil
+}
+
+// WarmBase caches the rates quoted against base, rebased from the fresh
+// canonical table rather than fetched, so that runs using base as their
+// canonical currency find them. The copy keeps the canonical timestamp and
+// expires with it. It reports whether the copy had to be written.
+func (api *ExchangeRateAPI) WarmBase(base string) (*RateInfo, bool, error) {
+	cached, err := api.cache.Get(api.base)
+	if err != nil {
+		return nil, false, err
+	}
+	if cached == nil {
+		return nil, false, fmt.Errorf("no fresh %s rates to rebase", api.base)
+	}
+
+	if existing, err := api.cache.Get(base); err == nil && existing != nil &&
+		!existing.Timestamp.Before(cached.Timestamp) {
+		_, info := fromCache(existing)
+		return info, false, nil
+	}
+
+	table, _ := fromCache(cached)
+	rates, _, err := table.Rebase(base)
+	if err != nil {
+		return nil, false, err
+	}
+	bid, ask := table.RebaseBidAsk(base)
+	data := &CacheData{
+		Base:          base,
+		Rates:         rates,
+		Source:        fmt.Sprintf("%s (via %s)", cached.Source, api.base),
+		Bid:           bid,
+		Ask:           ask,
+		EffectiveDate: cached.EffectiveDate,
+		PayloadDigest: cached.PayloadDigest,
+	}
+	if err := api.cache.PutAt(data, cached.Timestamp); err != nil {
+		return nil, false, err
+	}
+	_, info := fromCache(data)
+	return info, true, nil
+}
+
+// lock creates a lock file in the cache directory. A lock older than maxAge
+// is considered abandoned and taken over.
+func (c *Cache) lock(name string, maxAge time.Duration) bool {
+	return lockFile(filepath.Join(c.cacheDir, name+".lock"), maxAge)
+}
+
+func (c *Cache) unlock(name string) {
+	os.Remove(filepath.Join(c.cacheDir, name+".lock"))
+}
+
+func getMaxStale() time.Duration {
+	if env := os.Getenv("S_CALC_MAX_STALE"); env != "" {
+		if parsed, err := strconv.ParseFloat(env, 64); err == nil && parsed >= 0 {
+			return time.Duration(parsed * float64(time.Hour))
+		}
+	}
+	return defaultMaxStaleHours * time.Hour
+}
@@ -0,0 +1,48 @@
+package exchangerate
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWarmBaseRebasesCanonicalTable(t *testing.T) {
+	api := newTestAPI()
+	if _, _, err := api.WarmBase("PLN"); err == nil {
+		t.Fatal("WarmBase() without canonical rates succeeded")
+	}
+
+	fetched := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	if err := api.cache.PutAt(&CacheData{
+		Base:   "EUR",
+		Rates:  map[string]float64{"EUR": 1, "PLN": 4, "USD": 2},
+		Source: "test",
+	}, fetched); err != nil {
+		t.Fatal(err)
+	}
+
+	info, warmed, err := api.WarmBase("PLN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !warmed || info.Source != "test (via EUR)" || !info.Timestamp.Equal(fetched) {
+		t.Errorf("WarmBase() = %+v, %v; want a copy from test dated %v", info, warmed, fetched)
+	}
+
+	cached, err := api.cache.Get("PLN")
+	if err != nil || cached == nil {
+		t.Fatalf("PLN cache = %v, %v", cached, err)
+	}
+	for currency, want := range map[string]float64{"PLN": 1, "EUR": 0.25, "USD": 0.5} {
+		if math.Abs(cached.Rates[currency]-want) > 1e-12 {
+			t.Errorf("PLN->%s = %v, want %v", currency, cached.Rates[currency], want)
+		}
+	}
+	if !cached.ExpiresAt.Equal(fetched.Add(api.cache.ttl)) {
+		t.Errorf("PLN cache expires %v, want with the EUR table", cached.ExpiresAt)
+	}
+
+	if _, warmed, err := api.WarmBase("PLN"); err != nil || warmed {
+		t.Errorf("second WarmBase() warmed = %v, %v; want the copy kept", warmed, err)
+	}
+}
@@ -0,0 +1,133 @@
+package exchangerate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CacheSchemaVersion is the version of the cache file format written by Put.
+// Files without a version field are version 1.
+const CacheSchemaVersion = 2
+
+// ErrCacheCorrupt is returned for cache files whose checksum does not match
+// their content.
+var ErrCacheCorrupt = errors.New("cache file is corrupt")
+
+// cacheMigrations[v] upgrades a decoded cache file from version v to v+1.
+// base is the currency the file is stored under.
+var cacheMigrations = map[int]func(raw map[string]json.RawMessage, base string) error{
+	1: migrateCacheV1,
+}
+
+// migrateCacheV1 upgrades unversioned files, which may lack the base field
+// and the base's own rate.
+func migrateCacheV1(raw map[string]json.RawMessage, base string) error {
+	if _, ok := raw["base"]; !ok {
+		raw["base"], _ = json.Marshal(base)
+	}
+
+	var rates map[string]float64
+	if err := json.Unmarshal(raw["rates"], &rates); err != nil {
+		return err
+	}
+	if _, ok := rates[base]; !ok && rates != nil {
+		rates[base] = 1.0
+		raw["rates"], _ = json.Marshal(rates)
+	}
+	return nil
+}
+
+// decodeCacheData parses a cache file stored under base, migrating it to the
+// current schema version. migrated reports whether it was an older version.
+func decodeCacheData(data []byte, base string) (cacheData *CacheData, migrated bool, err error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, false, err
+	}
+
+	version := 1
+	if v, ok := raw["version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return nil, false, fmt.Errorf("invalid version: %w", err)
+		}
+	}
+	if version > CacheSchemaVersion {
+		return nil, false, fmt.Errorf("schema version %d is newer than supported version %d", version, CacheSchemaVersion)
+	}
+
+	for ; version < CacheSchemaVersion; version++ {
+		migrate, ok := cacheMigrations[version]
+		if !ok {
+			return nil, false, fmt.Errorf("no migration from schema version %d", version)
+		}
+		if err := migrate(raw, base); err != nil {
+			return nil, false, fmt.Errorf("migrating from schema version %d: %w", version, err)
+		}
+		migrated = true
+	}
+	raw["version"], _ = json.Marshal(CacheSchemaVersion)
+
+	if data, err = json.Marshal(raw); err != nil {
+		return nil, false, err
+	}
+	cacheData = &CacheData{}
+	if err := json.Unmarshal(data, cacheData); err != nil {
+		return nil, false, err
+	}
+
+	if !migrated && cacheData.Checksum != cacheData.checksum() {
+		return nil, false, ErrCacheCorrupt
+	}
+	return cacheData, migrated, nil
+}
+
+// checksum is the SHA-256 of the data's JSON encoding without the checksum
+// itself. Map keys are encoded in sorted order, so it is stable.
+func (d *CacheData) checksum() string {
+	unsummed := *d
+	unsummed.Checksum = ""
+	data, err := json.Marshal(&unsummed)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// payloadDigest identifies the raw provider response rates were parsed from.
+func payloadDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so that readers, including ones on other hosts sharing the
+// directory, never see a partially written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
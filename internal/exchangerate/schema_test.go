@@ -0,0 +1,96 @@
+package exchangerate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestCache() *Cache {
+	return &Cache{cacheDir: "", store: NewMemoryStore(1024), backend: BackendMemory, ttl: time.Hour}
+}
+
+func TestCacheLoadMigratesV1(t *testing.T) {
+	c := newTestCache()
+	v1 := []byte(`{
+  "rates": {"PLN": 4.25, "USD": 1.1},
+  "timestamp": "2026-10-01T12:00:00Z",
+  "source": "exchangerate-api.com",
+  "expires_at": "2026-10-02T12:00:00Z"
+}`)
+	if err := c.store.Put(ratesKey("EUR"), v1); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := c.Load("EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Version != CacheSchemaVersion || data.Base != "EUR" {
+		t.Errorf("version %d, base %q; want %d, EUR", data.Version, data.Base, CacheSchemaVersion)
+	}
+	if data.Rates["EUR"] != 1 || data.Rates["PLN"] != 4.25 {
+		t.Errorf("rates = %v, want EUR 1 and PLN 4.25", data.Rates)
+	}
+	if data.Source != "exchangerate-api.com" || !data.ExpiresAt.Equal(time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("source %q, expires %v not kept", data.Source, data.ExpiresAt)
+	}
+
+	// The migrated file is written back with a valid checksum.
+	stored, err := c.store.Get(ratesKey("EUR"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewritten, migrated, err := decodeCacheData(stored, "EUR")
+	if err != nil {
+		t.Fatalf("rewritten file: %v", err)
+	}
+	if migrated || rewritten.Checksum == "" {
+		t.Errorf("rewritten file migrated=%v checksum=%q, want current version with checksum", migrated, rewritten.Checksum)
+	}
+}
+
+func TestCacheLoadRejectsTamperedChecksum(t *testing.T) {
+	c := newTestCache()
+	if err := c.Put(&CacheData{Base: "EUR", Rates: map[string]float64{"EUR": 1, "PLN": 4.25}, Source: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Load("EUR"); err != nil {
+		t.Fatalf("untampered file: %v", err)
+	}
+
+	stored, _ := c.store.Get(ratesKey("EUR"))
+	tampered := bytes.Replace(stored, []byte("4.25"), []byte("4.52"), 1)
+	if bytes.Equal(stored, tampered) {
+		t.Fatal("fixture does not contain the rate to tamper with")
+	}
+	if err := c.store.Put(ratesKey("EUR"), tampered); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Load("EUR"); !errors.Is(err, ErrCacheCorrupt) {
+		t.Errorf("Load() error = %v, want ErrCacheCorrupt", err)
+	}
+}
+
+func TestCacheLoadRejectsNewerVersion(t *testing.T) {
+	c := newTestCache()
+	newer, _ := json.Marshal(map[string]any{
+		"version": CacheSchemaVersion + 1,
+		"base":    "EUR",
+		"rates":   map[string]float64{"EUR": 1},
+	})
+	if err := c.store.Put(ratesKey("EUR"), newer); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Load("EUR"); err == nil {
+		t.Error("Load() accepted a newer schema version")
+	}
+	// The newer file is left for the newer version that wrote it.
+	if stored, _ := c.store.Get(ratesKey("EUR")); !bytes.Equal(stored, newer) {
+		t.Error("Load() rewrote a newer schema version")
+	}
+}
@@ -0,0 +1,209 @@
+package exchangerate
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store holds cache and history documents by key. Keys are slash-separated,
+// e.g. "rates/EUR", and values are JSON documents.
+type Store interface {
+	// Get returns nil and no error when key is not stored.
+	Get(key string) ([]byte, error)
+	Put(key string, value []byte) error
+	Delete(key string) error
+	// Keys returns the stored keys starting with prefix, sorted.
+	Keys(prefix string) ([]string, error)
+}
+
+// Backend names a Store implementation.
+type Backend string
+
+const (
+	BackendFile   Backend = "file"
+	BackendMemory Backend = "memory"
+	BackendKV     Backend = "kv"
+)
+
+const defaultMemoryCapacity = 256
+
+func ParseBackend(s string) (Backend, error) {
+	switch backend := Backend(strings.ToLower(s)); backend {
+	case "":
+		return BackendFile, nil
+	case BackendFile, BackendMemory, BackendKV:
+		return backend, nil
+	}
+	return "", fmt.Errorf("invalid cache backend: %s (supported: file, memory, kv)", s)
+}
+
+// OpenStore opens the store for backend in dir.
+func OpenStore(backend Backend, dir string) (Store, error) {
+	switch backend {
+	case BackendFile, "":
+		return &fileStore{dir: dir}, nil
+	case BackendMemory:
+		return NewMemoryStore(defaultMemoryCapacity), nil
+	case BackendKV:
+		return OpenKVStore(filepath.Join(dir, kvFileName))
+	}
+	return nil, fmt.Errorf("invalid cache backend: %s (supported: file, memory, kv)", backend)
+}
+
+// fileStore keeps one JSON file per key, e.g. "rates/EUR" in rates-EUR.json.
+type fileStore struct {
+	dir string
+}
+
+func (s *fileStore) path(key string) string {
+	return filepath.Join(s.dir, strings.ReplaceAll(key, "/", "-")+".json")
+}
+
+func (s *fileStore) Get(key string) ([]byte, error) {
+	data, err := os.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (s *fileStore) Put(key string, value []byte) error {
+	return writeFileAtomic(s.path(key), value, 0644)
+}
+
+func (s *fileStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *fileStore) Keys(prefix string) ([]string, error) {
+	pattern := strings.ReplaceAll(prefix, "/", "-") + "*.json"
+	paths, err := filepath.Glob(filepath.Join(s.dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(paths))
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		keys = append(keys, prefix+strings.TrimPrefix(name, strings.ReplaceAll(prefix, "/", "-")))
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// stat returns the file behind key, for listings.
+func (s *fileStore) stat(key string) (string, os.FileInfo, error) {
+	path := s.path(key)
+	info, err := os.Stat(path)
+	return path, info, err
+}
+
+// MemoryStore is an in-process Store that keeps at most capacity keys,
+// evicting the least recently used.
+type MemoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type memoryItem struct {
+	key   string
+	value []byte
+}
+
+func NewMemoryStore(capacity int) *MemoryStore {
+	return &MemoryStore{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (s *MemoryStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, nil
+	}
+	s.order.MoveToFront(elem)
+	return append([]byte(nil), elem.Value.(*memoryItem).value...), nil
+}
+
+func (s *MemoryStore) Put(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value = append([]byte(nil), value...)
+	if elem, ok := s.items[key]; ok {
+		elem.Value.(*memoryItem).value = value
+		s.order.MoveToFront(elem)
+		return nil
+	}
+
+	s.items[key] = s.order.PushFront(&memoryItem{key: key, value: value})
+	for s.capacity > 0 && s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*memoryItem).key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		s.order.Remove(elem)
+		delete(s.items, key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Keys(prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var keys []string
+	for key := range s.items {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// lockFile creates path exclusively. A lock older than maxAge is considered
+// abandoned and taken over.
+func lockFile(path string, maxAge time.Duration) bool {
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return true
+		}
+		if !os.IsExist(err) {
+			return false
+		}
+
+		info, err := os.Stat(path)
+		if err != nil || time.Since(info.ModTime()) < maxAge {
+			return false
+		}
+		os.Remove(path)
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package exchangerate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testStore checks the behavior every Store must have.
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+
+	if value, err := s.Get("rates/EUR"); err != nil || value != nil {
+		t.Fatalf("Get(missing) = %q, %v; want nil, nil", value, err)
+	}
+
+	for _, key := range []string{"rates/USD", "rates/EUR", "other/EUR"} {
+		if err := s.Put(key, []byte(fmt.Sprintf(`{"key":%q}`, key))); err != nil {
+			t.Fatalf("Put(%s): %v", key, err)
+		}
+	}
+	if err := s.Put("rates/EUR", []byte(`{"key":"rates/EUR","v":2}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := s.Get("rates/EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(value, &doc); err != nil || doc["v"] != float64(2) {
+		t.Errorf("Get(rates/EUR) = %s, want the latest value", value)
+	}
+
+	keys, err := s.Keys("rates/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"rates/EUR", "rates/USD"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("Keys(rates/) = %v, want %v", keys, want)
+	}
+
+	if err := s.Delete("rates/EUR"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("rates/EUR"); err != nil {
+		t.Errorf("Delete(missing) = %v, want nil", err)
+	}
+	if value, _ := s.Get("rates/EUR"); value != nil {
+		t.Errorf("Get after Delete = %s, want nil", value)
+	}
+	if keys, _ := s.Keys("rates/"); !reflect.DeepEqual(keys, []string{"rates/USD"}) {
+		t.Errorf("Keys after Delete = %v, want [rates/USD]", keys)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	testStore(t, &fileStore{dir: t.TempDir()})
+}
+
+func TestFileStoreKeysRoundTrip(t *testing.T) {
+	s := &fileStore{dir: t.TempDir()}
+	if err := s.Put(ratesKey("EUR"), []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(s.dir, "rates-EUR.json")); err != nil {
+		t.Errorf("rates/EUR not stored as rates-EUR.json: %v", err)
+	}
+
+	keys, err := s.Keys("rates/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, []string{"rates/EUR"}) {
+		t.Fatalf("Keys(rates/) = %v, want [rates/EUR]", keys)
+	}
+	if value, err := s.Get(keys[0]); err != nil || string(value) != "{}" {
+		t.Errorf("Get(%s) = %q, %v", keys[0], value, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore(8))
+}
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	s := NewMemoryStore(2)
+	s.Put("a", []byte(`1`))
+	s.Put("b", []byte(`2`))
+	s.Get("a") // b is now the least recently used
+	s.Put("c", []byte(`3`))
+
+	keys, _ := s.Keys("")
+	if !reflect.DeepEqual(keys, []string{"a", "c"}) {
+		t.Errorf("keys = %v, want [a c]", keys)
+	}
+}
+
+func TestKVStore(t *testing.T) {
+	s, err := OpenKVStore(filepath.Join(t.TempDir(), kvFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+
+	if err := s.Put("rates/GBP", []byte(`not json`)); err == nil {
+		t.Error("Put accepted a value that is not JSON")
+	}
+}
+
+func TestKVStoreSharedBetweenProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), kvFileName)
+	a, err := OpenKVStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := OpenKVStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.Put("rates/EUR", []byte(`{"from":"a"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := b.Get("rates/EUR"); string(value) != `{"from":"a"}` {
+		t.Errorf("other store sees %s, want a's write", value)
+	}
+	if err := b.Delete("rates/EUR"); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := a.Get("rates/EUR"); value != nil {
+		t.Errorf("other store's delete not seen: %s", value)
+	}
+}
+
+func TestKVStoreCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), kvFileName)
+	s, err := OpenKVStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i <= kvCompactMin; i++ {
+		if err := s.Put(fmt.Sprintf("rates/%d", i%3), []byte(fmt.Sprintf(`{"n":%d}`, i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reopening compacts the log down to the three live keys.
+	s, err = OpenKVStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.records != 3 {
+		t.Errorf("records after compaction = %d, want 3", s.records)
+	}
+	for i := 0; i < 3; i++ {
+		want := kvCompactMin - (kvCompactMin-i)%3
+		value, _ := s.Get(fmt.Sprintf("rates/%d", i))
+		if string(value) != fmt.Sprintf(`{"n":%d}`, want) {
+			t.Errorf("rates/%d = %s, want n %d", i, value, want)
+		}
+	}
+}
+
+func TestKVStoreWriteWaitsForCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), kvFileName)
+	s, err := OpenKVStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put("rates/EUR", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process is compacting: the write must not append to the log
+	// that is about to be replaced.
+	lock := path + ".lock"
+	if !lockFile(lock, kvLockMaxAge) {
+		t.Fatal("could not take the lock")
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Put("rates/USD", []byte(`{}`)) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Put returned %v while the log was locked", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := os.WriteFile(path, []byte(`{"k":"rates/EUR","v":{}}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(lock)
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	other, err := OpenKVStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := other.Keys("rates/"); !reflect.DeepEqual(keys, []string{"rates/EUR", "rates/USD"}) {
+		t.Errorf("keys after compaction = %v, want the write kept", keys)
+	}
+}
@@ -0,0 +1,86 @@
+package exchangerate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidRates is returned when a provider payload fails validation.
+var ErrInvalidRates = errors.New("invalid rates")
+
+const defaultMaxDailyChange = 10.0
+
+// validatePayload checks the fields every provider response must satisfy:
+// the quoted base matches the requested one and all rates are positive numbers.
+func validatePayload(baseCurrency, reportedBase string, rates map[string]float64) error {
+	if reportedBase != "" && reportedBase != baseCurrency {
+		return fmt.Errorf("%w: requested base %s, got %s", ErrInvalidRates, baseCurrency, reportedBase)
+	}
+
+	if len(rates) == 0 {
+		return fmt.Errorf("%w: response contains no rates", ErrInvalidRates)
+	}
+
+	for _, currency := range sortedKeys(rates) {
+		rate := rates[currency]
+		if math.IsNaN(rate) || math.IsInf(rate, 0) || rate <= 0 {
+			return fmt.Errorf("%w: %s rate is %v", ErrInvalidRates, currency, rate)
+		}
+	}
+
+	return nil
+}
+
+// validateRates checks that all required currencies are present and that no
+// rate moved more than maxDailyChange percent per day since the previous set.
+func (api *ExchangeRateAPI) validateRates(rates map[string]float64, previous *CacheData) error {
+	for _, currency := range api.required {
+		if _, ok := rates[currency]; !ok {
+			return fmt.Errorf("%w: missing %s rate", ErrInvalidRates, currency)
+		}
+	}
+
+	if previous == nil || previous.Base != api.base {
+		return nil
+	}
+
+	days := math.Max(1, time.Since(previous.Timestamp).Hours()/24)
+	limit := api.maxDailyChange * days
+
+	for _, currency := range sortedKeys(rates) {
+		before, ok := previous.Rates[currency]
+		if !ok || before <= 0 {
+			continue
+		}
+		change := (rates[currency] - before) / before * 100
+		if math.Abs(change) > limit {
+			return fmt.Errorf("%w: %s moved %+.2f%% since %s (limit %.2f%%)", ErrInvalidRates,
+				currency, change, previous.Timestamp.Format("2006-01-02"), limit)
+		}
+	}
+
+	return nil
+}
+
+func getMaxDailyChange() float64 {
+	if env := os.Getenv("S_CALC_MAX_DAILY_CHANGE"); env != "" {
+		if parsed, err := strconv.ParseFloat(env, 64); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultMaxDailyChange
+}
+
+func sortedKeys(rates map[string]float64) []string {
+	keys := make([]string, 0, len(rates))
+	for k := range rates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,211 @@
+package locale
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Locale describes how numbers and money amounts are written.
+type Locale struct {
+	Tag     string
+	Group   string
+	Decimal string
+	// SymbolFirst places the currency symbol before the amount.
+	SymbolFirst bool
+	// SymbolSpace separates the symbol from the amount with a space.
+	SymbolSpace bool
+}
+
+var (
+	EnUS = Locale{Tag: "en-US", Group: ",", Decimal: ".", SymbolFirst: true}
+	EnGB = Locale{Tag: "en-GB", Group: ",", Decimal: ".", SymbolFirst: true}
+	PlPL = Locale{Tag: "pl-PL", Group: " ", Decimal: ",", SymbolSpace: true}
+	DeDE = Locale{Tag: "de-DE", Group: ".", Decimal: ",", SymbolSpace: true}
+)
+
+var ValidLocales = []Locale{PlPL, DeDE, EnGB, EnUS}
+
+var symbols = map[string]string{
+	"PLN": "zł",
+	"EUR": "€",
+	"USD": "$",
+	"GBP": "£",
+	"JPY": "¥",
+}
+
+// minorUnits lists currencies whose amounts are not written with two decimals.
+var minorUnits = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"HUF": 0,
+	"ISK": 0,
+	"CLP": 0,
+	"BHD": 3,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+}
+
+// Parse looks up a locale by tag. It accepts "pl-PL", POSIX forms such as
+// "pl_PL.UTF-8", and a bare language such as "pl".
+func Parse(tag string) (Locale, error) {
+	normalized := tag
+	if i := strings.IndexAny(normalized, ".@"); i >= 0 {
+		normalized = normalized[:i]
+	}
+	normalized = strings.ReplaceAll(normalized, "_", "-")
+
+	for _, l := range ValidLocales {
+		if strings.EqualFold(l.Tag, normalized) {
+			return l, nil
+		}
+	}
+	for _, l := range ValidLocales {
+		language, _, _ := strings.Cut(l.Tag, "-")
+		if strings.EqualFold(language, normalized) {
+			return l, nil
+		}
+	}
+
+	return Locale{}, fmt.Errorf("unsupported locale: %s (supported: pl-PL, de-DE, en-GB, en-US)", tag)
+}
+
+// FromEnv picks the locale from LC_ALL, LC_NUMERIC or LANG, defaulting to en-US.
+func FromEnv() Locale {
+	for _, name := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" || value == "C" || value == "POSIX" {
+			continue
+		}
+		if l, err := Parse(value); err == nil {
+			return l
+		}
+	}
+	return EnUS
+}
+
+// Resolve returns the locale for tag, or the environment's locale if tag is empty.
+func Resolve(tag string) (Locale, error) {
+	if tag == "" {
+		return FromEnv(), nil
+	}
+	return Parse(tag)
+}
+
+// MinorUnits returns the number of decimals amounts in currency are written with.
+func MinorUnits(currency string) int {
+	if units, ok := minorUnits[currency]; ok {
+		return units
+	}
+	return 2
+}
+
+// Symbol returns the currency's symbol, or its code when it has none.
+func Symbol(currency string) string {
+	if symbol, ok := symbols[currency]; ok {
+		return symbol
+	}
+	return currency
+}
+
+// FormatNumber formats n with the given number of decimals and the locale's separators.
+func (l Locale) FormatNumber(n float64, decimals int) string {
+	formatted := strconv.FormatFloat(math.Abs(n), 'f', decimals, 64)
+	intPart, fracPart, _ := strings.Cut(formatted, ".")
+
+	if len(intPart) > 3 {
+		var result strings.Builder
+		start := len(intPart) % 3
+		if start > 0 {
+			result.WriteString(intPart[:start])
+		}
+		for i := start; i < len(intPart); i += 3 {
+			if i > 0 {
+				result.WriteString(l.Group)
+			}
+			result.WriteString(intPart[i : i+3])
+		}
+		intPart = result.String()
+	}
+
+	sign := ""
+	if n < 0 && formatted != strconv.FormatFloat(0, 'f', decimals, 64) {
+		sign = "-"
+	}
+
+	if fracPart != "" {
+		return sign + intPart + l.Decimal + fracPart
+	}
+	return sign + intPart
+}
+
+// FormatAmount formats n with the currency's minor units, without a symbol.
+func (l Locale) FormatAmount(n float64, currency string) string {
+	return l.FormatNumber(n, MinorUnits(currency))
+}
+
+// FormatMoney formats n as an amount of currency, placing the symbol as the locale does.
+func (l Locale) FormatMoney(n float64, currency string) string {
+	amount := l.FormatAmount(n, currency)
+	symbol := Symbol(currency)
+
+	space := ""
+	if l.SymbolSpace || symbol == currency {
+		space = " "
+	}
+
+	if l.SymbolFirst {
+		if strings.HasPrefix(amount, "-") {
+			return "-" + symbol + space + amount[1:]
+		}
+		return symbol + space + amount
+	}
+	return amount + space + symbol
+}
+
+// ParseNumber parses a number written in the locale, e.g. "14 743,40" in pl-PL.
+// Input that is not valid in the locale, such as "1234.5", is parsed as a
+// plain number.
+func (l Locale) ParseNumber(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+
+	if normalized, ok := l.normalize(s); ok {
+		if value, err := strconv.ParseFloat(normalized, 64); err == nil {
+			return value, nil
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number: %s", s)
+	}
+	return value, nil
+}
+
+// normalize rewrites s from the locale's notation to Go's, reporting false
+// unless every digit group after the first is three digits long.
+func (l Locale) normalize(s string) (string, bool) {
+	intPart, fracPart, hasFrac := strings.Cut(s, l.Decimal)
+	if strings.Contains(fracPart, l.Group) {
+		return "", false
+	}
+
+	groups := strings.Split(intPart, l.Group)
+	if l.Group == " " {
+		groups = strings.FieldsFunc(intPart, func(r rune) bool { return r == ' ' || r == '\u00a0' })
+	}
+	for i, group := range groups {
+		if i > 0 && len(group) != 3 {
+			return "", false
+		}
+	}
+
+	normalized := strings.Join(groups, "")
+	if hasFrac {
+		normalized += "." + fracPart
+	}
+	return normalized, true
+}
@@ -0,0 +1,147 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"salary-calc/internal/exchangerate"
+)
+
+// FormatCacheList lists cached rate sets with their age, source and expiry.
+// location describes where the cache is kept.
+func FormatCacheList(location string, entries []exchangerate.CacheEntry, now time.Time, opts Options) string {
+	if len(entries) == 0 {
+		return fmt.Sprintf("No cached rates in %s.\n", location)
+	}
+
+	g := &grid{
+		header: []string{"Base", "Age", "Source", "Expires", "Rates", "Size"},
+		align:  []alignment{alignLeft, alignRight, alignLeft, alignLeft, alignRight, alignRight},
+	}
+	for _, entry := range entries {
+		size := formatSize(entry.Size)
+		if entry.Data == nil {
+			g.rows = append(g.rows, []cell{
+				{text: entry.Base},
+				{text: formatModTime(entry.ModTime, now)},
+				{text: unreadableReason(entry.Err), color: ansiRed},
+				{text: "-"},
+				{text: "-"},
+				{text: size},
+			})
+			continue
+		}
+
+		expires := cell{text: entry.Data.ExpiresAt.Format("2006-01-02 15:04")}
+		if now.After(entry.Data.ExpiresAt) {
+			expires.text += " (expired)"
+			expires.color = ansiYellow
+		}
+		g.rows = append(g.rows, []cell{
+			{text: entry.Base},
+			{text: formatAge(now.Sub(entry.Data.Timestamp))},
+			{text: entry.Data.Source},
+			expires,
+			{text: fmt.Sprint(len(entry.Data.Rates))},
+			{text: size},
+		})
+	}
+
+	var sb strings.Builder
+	sb.WriteString(g.render(opts.Theme, terminalWidth()))
+	sb.WriteString(fmt.Sprintf("\nCache: %s\n", location))
+	return sb.String()
+}
+
+// FormatCacheData pretty-prints one cached rate set.
+func FormatCacheData(data *exchangerate.CacheData, now time.Time, opts Options) string {
+	loc := opts.Locale
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Base: %s\n", data.Base))
+	sb.WriteString(fmt.Sprintf("Source: %s\n", data.Source))
+	sb.WriteString(fmt.Sprintf("Fetched at: %s (%s ago)\n", data.Timestamp.Format(time.RFC3339), formatAge(now.Sub(data.Timestamp))))
+	expires := fmt.Sprintf("Expires at: %s", data.ExpiresAt.Format(time.RFC3339))
+	if now.After(data.ExpiresAt) {
+		expires = opts.Theme.paint(ansiYellow, expires+" (expired)")
+	}
+	sb.WriteString(expires + "\n")
+	if data.EffectiveDate != "" {
+		sb.WriteString(fmt.Sprintf("Effective date: %s\n", data.EffectiveDate))
+	}
+	if data.PayloadDigest != "" {
+		sb.WriteString(fmt.Sprintf("Payload digest: %s\n", data.PayloadDigest))
+	}
+	sb.WriteString(formatQuota(data.Quota))
+	sb.WriteString(fmt.Sprintf("Schema version: %d\n\n", data.Version))
+
+	header := []string{"Currency", "Rate"}
+	align := []alignment{alignLeft, alignRight}
+	hasBidAsk := len(data.Bid) > 0 && len(data.Ask) > 0
+	if hasBidAsk {
+		header = append(header, "Bid", "Ask")
+		align = append(align, alignRight, alignRight)
+	}
+
+	g := &grid{header: header, align: align}
+	for _, currency := range sortedCurrencies(data.Rates) {
+		row := plainCells(currency, loc.FormatNumber(data.Rates[currency], 6))
+		if hasBidAsk {
+			row = append(row, plainCells(formatOptionalRate(data.Bid, currency, opts), formatOptionalRate(data.Ask, currency, opts))...)
+		}
+		g.rows = append(g.rows, row)
+	}
+	sb.WriteString(g.render(opts.Theme, terminalWidth()))
+
+	return sb.String()
+}
+
+func unreadableReason(err error) string {
+	if errors.Is(err, exchangerate.ErrCacheCorrupt) {
+		return "corrupt (checksum mismatch)"
+	}
+	return "unreadable"
+}
+
+func formatOptionalRate(rates map[string]float64, currency string, opts Options) string {
+	rate, ok := rates[currency]
+	if !ok {
+		return "-"
+	}
+	return opts.Locale.FormatNumber(rate, 6)
+}
+
+func formatModTime(modTime, now time.Time) string {
+	if modTime.IsZero() {
+		return "-"
+	}
+	return formatAge(now.Sub(modTime))
+}
+
+// formatAge formats a duration to its two largest units, e.g. "2d 3h".
+func formatAge(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+func formatSize(bytes int64) string {
+	if bytes < 1024 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
+}
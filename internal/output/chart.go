@@ -0,0 +1,189 @@
+package output
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"salary-calc/internal/exchangerate"
+	"salary-calc/internal/locale"
+)
+
+var sparkGlyphs = []rune("▁▂▃▄▅▆▇█")
+
+var sparkGlyphsASCII = []rune("_.-~=*#@")
+
+// Sparkline renders values as a single line of block glyphs.
+func Sparkline(values []float64, theme Theme) string {
+	glyphs := sparkGlyphs
+	if theme.ascii {
+		glyphs = sparkGlyphsASCII
+	}
+
+	lo, hi := valueRange(values)
+
+	var sb strings.Builder
+	for _, v := range values {
+		idx := 0
+		if hi > lo {
+			idx = int(math.Round((v - lo) / (hi - lo) * float64(len(glyphs)-1)))
+		}
+		sb.WriteRune(glyphs[idx])
+	}
+	return sb.String()
+}
+
+type chartGlyphs struct {
+	flat, vertical, axis                     string
+	riseTop, riseBottom, fallTop, fallBottom string
+}
+
+var unicodeChart = chartGlyphs{
+	flat: "─", vertical: "│", axis: "┤",
+	riseTop: "╭", riseBottom: "╯", fallTop: "╮", fallBottom: "╰",
+}
+
+var asciiChart = chartGlyphs{
+	flat: "-", vertical: "|", axis: "|",
+	riseTop: "/", riseBottom: "/", fallTop: "\\", fallBottom: "\\",
+}
+
+// FormatChart draws points as a line chart of at most width columns and
+// height rows, with the value axis on the left and dates underneath.
+func FormatChart(title string, points []exchangerate.Point, width, height int, opts Options) string {
+	if len(points) == 0 {
+		return fmt.Sprintf("No data to chart for %s.\n", title)
+	}
+
+	loc := opts.Locale
+	g := unicodeChart
+	if opts.Theme.ascii {
+		g = asciiChart
+	}
+
+	values := resample(points, width)
+	lo, hi := valueRange(values)
+	if hi == lo {
+		hi = lo + 1
+	}
+
+	rowOf := func(v float64) int {
+		return int(math.Round((v - lo) / (hi - lo) * float64(height-1)))
+	}
+
+	grid := make([][]string, height)
+	for r := range grid {
+		grid[r] = make([]string, len(values))
+		for c := range grid[r] {
+			grid[r][c] = " "
+		}
+	}
+
+	prev := rowOf(values[0])
+	for c, v := range values {
+		cur := rowOf(v)
+		switch {
+		case c == 0 || cur == prev:
+			grid[cur][c] = g.flat
+		case cur > prev:
+			grid[prev][c] = g.riseBottom
+			grid[cur][c] = g.riseTop
+			for r := prev + 1; r < cur; r++ {
+				grid[r][c] = g.vertical
+			}
+		default:
+			grid[prev][c] = g.fallTop
+			grid[cur][c] = g.fallBottom
+			for r := cur + 1; r < prev; r++ {
+				grid[r][c] = g.vertical
+			}
+		}
+		prev = cur
+	}
+
+	labels := make([]string, height)
+	labelWidth := 0
+	for r := range labels {
+		labels[r] = formatAxisValue(lo+(hi-lo)*float64(r)/float64(max(height-1, 1)), loc)
+		labelWidth = max(labelWidth, len(labels[r]))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(title)
+	sb.WriteString("\n\n")
+	for r := height - 1; r >= 0; r-- {
+		sb.WriteString(padLeft(labels[r], labelWidth))
+		sb.WriteString(" ")
+		sb.WriteString(g.axis)
+		sb.WriteString(strings.Join(grid[r], ""))
+		sb.WriteString("\n")
+	}
+
+	first := points[0].Date.Format("2006-01-02")
+	last := points[len(points)-1].Date.Format("2006-01-02")
+	gap := len(values) + 1 - len(first) - len(last)
+	sb.WriteString(strings.Repeat(" ", labelWidth+1))
+	sb.WriteString(first)
+	if gap > 0 {
+		sb.WriteString(strings.Repeat(" ", gap))
+	} else {
+		sb.WriteString(" - ")
+	}
+	sb.WriteString(last)
+	sb.WriteString("\n")
+
+	rates := make([]float64, len(points))
+	for i, p := range points {
+		rates[i] = p.Rate
+	}
+	pmin, pmax, _ := seriesStats(points)
+	sb.WriteString(fmt.Sprintf("\n%s  min %s  max %s  last %s  (%s%%)\n",
+		Sparkline(resampleValues(rates, 40), opts.Theme),
+		formatAxisValue(pmin, loc), formatAxisValue(pmax, loc), formatAxisValue(rates[len(rates)-1], loc),
+		opts.formatSigned((rates[len(rates)-1]-rates[0])/rates[0]*100)))
+
+	return sb.String()
+}
+
+func formatAxisValue(v float64, loc locale.Locale) string {
+	if math.Abs(v) >= 1000 {
+		return loc.FormatNumber(v, 2)
+	}
+	return loc.FormatNumber(v, 4)
+}
+
+func resample(points []exchangerate.Point, width int) []float64 {
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.Rate
+	}
+	return resampleValues(values, width)
+}
+
+// resampleValues averages values into at most width buckets.
+func resampleValues(values []float64, width int) []float64 {
+	if len(values) <= width {
+		return values
+	}
+
+	out := make([]float64, width)
+	for i := range out {
+		start := i * len(values) / width
+		end := (i + 1) * len(values) / width
+		var sum float64
+		for _, v := range values[start:end] {
+			sum += v
+		}
+		out[i] = sum / float64(end-start)
+	}
+	return out
+}
+
+func valueRange(values []float64) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	return lo, hi
+}
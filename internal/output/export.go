@@ -0,0 +1,248 @@
+package output
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+type markdownRenderer struct {
+	opts Options
+}
+
+func (r *markdownRenderer) Render(report *Report) (string, error) {
+	loc := r.opts.Locale
+	var sb strings.Builder
+
+	header, rows := report.layout()
+	sb.WriteString("| " + strings.Join(header, " | ") + " |\n|:-------|")
+	sb.WriteString(strings.Repeat("------:|", len(header)-1) + "\n")
+
+	for _, row := range rows {
+		if row.received {
+			sb.WriteString("| _recv_ |")
+		} else {
+			sb.WriteString("| " + row.label + " |")
+		}
+		for _, c := range row.cells {
+			value := loc.FormatAmount(c.value, string(c.currency))
+			switch {
+			case row.received:
+			case report.isOriginal(c.period, c.currency):
+				value = "**" + value + "** ⭐"
+			case report.isManual(c.currency):
+				value = "_" + value + "_ ✎"
+			}
+			sb.WriteString(" " + value + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n⭐ Original input: **")
+	sb.WriteString(loc.FormatMoney(report.Input.Amount, string(report.Input.Currency)))
+	sb.WriteString("/" + strings.ToLower(string(report.Input.Period)) + "**\n")
+	if manual := manualCurrencies(report.RateInfo); len(manual) > 0 {
+		sb.WriteString("\n✎ Manual rate: " + strings.Join(manual, ", ") + "\n")
+	}
+	if report.Received != nil {
+		sb.WriteString("\n" + feeNote(report.Fees, report.Input.Currency) + "\n")
+	}
+
+	if lines := sourceLines(report.RateInfo); len(lines) > 0 {
+		sb.WriteString("\n")
+		for _, line := range lines {
+			sb.WriteString("- **" + line[0] + ":** " + line[1] + "\n")
+		}
+		if report.RateInfo.Stale {
+			sb.WriteString("\n> ⚠ " + staleNote(report.RateInfo) + "\n")
+		}
+	}
+
+	if report.Verbose && report.RateInfo != nil {
+		sb.WriteString("\n| Currency | Rate | Kind |\n|:---------|-----:|:-----|\n")
+		for _, currency := range sortedCurrencies(report.Rates) {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", currency,
+				loc.FormatNumber(report.Rates[currency], 4), report.RateInfo.Kinds[currency]))
+		}
+	}
+
+	return sb.String(), nil
+}
+
+const (
+	htmlTableStyle  = "border-collapse:collapse;font-family:Arial,Helvetica,sans-serif;font-size:14px;"
+	htmlHeaderStyle = "border:1px solid #ccc;padding:6px 10px;background:#f2f2f2;text-align:center;"
+	htmlCellStyle   = "border:1px solid #ccc;padding:6px 10px;text-align:right;"
+	htmlLabelStyle  = "border:1px solid #ccc;padding:6px 10px;text-align:left;font-weight:bold;"
+	htmlOrigStyle   = "background:#fff3bf;font-weight:bold;"
+	htmlManualStyle = "color:#862e9c;font-style:italic;"
+	htmlRecvStyle   = "color:#868e96;"
+	htmlNoteStyle   = "font-family:Arial,Helvetica,sans-serif;font-size:12px;color:#555;margin:8px 0;"
+)
+
+// htmlRenderer produces a self-contained snippet with inline styles only,
+// as email clients strip <style> blocks.
+type htmlRenderer struct {
+	opts Options
+}
+
+func (r *htmlRenderer) Render(report *Report) (string, error) {
+	loc := r.opts.Locale
+	var sb strings.Builder
+
+	sb.WriteString(`<table style="` + htmlTableStyle + `">` + "\n")
+	header, rows := report.layout()
+	sb.WriteString("  <tr>")
+	for _, h := range header {
+		sb.WriteString(`<th style="` + htmlHeaderStyle + `">` + html.EscapeString(h) + `</th>`)
+	}
+	sb.WriteString("</tr>\n")
+
+	for _, row := range rows {
+		sb.WriteString("  <tr>")
+		if row.received {
+			sb.WriteString(`<td style="` + htmlLabelStyle + htmlRecvStyle + `">recv</td>`)
+		} else {
+			sb.WriteString(`<td style="` + htmlLabelStyle + `">` + html.EscapeString(row.label) + `</td>`)
+		}
+		for _, c := range row.cells {
+			style := htmlCellStyle
+			value := html.EscapeString(loc.FormatAmount(c.value, string(c.currency)))
+			switch {
+			case row.received:
+				style += htmlRecvStyle
+			case report.isOriginal(c.period, c.currency):
+				style += htmlOrigStyle
+				value += " &#11088;"
+			case report.isManual(c.currency):
+				style += htmlManualStyle
+				value += " &#9998;"
+			}
+			sb.WriteString(`<td style="` + style + `">` + value + `</td>`)
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString("</table>\n")
+
+	var notes []string
+	notes = append(notes, "&#11088; Original input: <strong>"+
+		html.EscapeString(loc.FormatMoney(report.Input.Amount, string(report.Input.Currency))+"/"+strings.ToLower(string(report.Input.Period)))+
+		"</strong>")
+	if manual := manualCurrencies(report.RateInfo); len(manual) > 0 {
+		notes = append(notes, "&#9998; Manual rate: "+html.EscapeString(strings.Join(manual, ", ")))
+	}
+	if report.Received != nil {
+		notes = append(notes, html.EscapeString(feeNote(report.Fees, report.Input.Currency)))
+	}
+	for _, line := range sourceLines(report.RateInfo) {
+		notes = append(notes, html.EscapeString(line[0])+": "+html.EscapeString(line[1]))
+	}
+	if report.RateInfo != nil && report.RateInfo.Stale {
+		notes = append(notes, `<span style="color:#e67700;">&#9888; `+html.EscapeString(staleNote(report.RateInfo))+`</span>`)
+	}
+	sb.WriteString(`<p style="` + htmlNoteStyle + `">` + strings.Join(notes, "<br>\n") + "</p>\n")
+
+	if report.Verbose && report.RateInfo != nil {
+		sb.WriteString(`<table style="` + htmlTableStyle + `">` + "\n")
+		sb.WriteString(`  <tr><th style="` + htmlHeaderStyle + `">Currency</th><th style="` + htmlHeaderStyle +
+			`">Rate</th><th style="` + htmlHeaderStyle + `">Kind</th></tr>` + "\n")
+		for _, currency := range sortedCurrencies(report.Rates) {
+			sb.WriteString(fmt.Sprintf(`  <tr><td style="%s">%s</td><td style="%s">%s</td><td style="%s">%s</td></tr>`+"\n",
+				htmlLabelStyle, html.EscapeString(currency),
+				htmlCellStyle, html.EscapeString(loc.FormatNumber(report.Rates[currency], 4)),
+				htmlCellStyle, html.EscapeString(string(report.RateInfo.Kinds[currency]))))
+		}
+		sb.WriteString("</table>\n")
+	}
+
+	return sb.String(), nil
+}
+
+// latexRenderer produces a tabular that compiles without extra packages, so
+// money is written with currency codes rather than symbols.
+type latexRenderer struct {
+	opts Options
+}
+
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+func latexEscape(s string) string {
+	return latexEscaper.Replace(s)
+}
+
+func (r *latexRenderer) Render(report *Report) (string, error) {
+	loc := r.opts.Locale
+	var sb strings.Builder
+
+	header, rows := report.layout()
+	sb.WriteString(`\begin{tabular}{l` + strings.Repeat("r", len(header)-1) + "}\n")
+	sb.WriteString("\\hline\n")
+	for i, h := range header {
+		if i > 0 {
+			sb.WriteString(" & ")
+		}
+		sb.WriteString(`\textbf{` + latexEscape(h) + `}`)
+	}
+	sb.WriteString(" \\\\\n\\hline\n")
+
+	for _, row := range rows {
+		if row.received {
+			sb.WriteString(`\quad\textit{recv}`)
+		} else {
+			sb.WriteString(latexEscape(row.label))
+		}
+		for _, c := range row.cells {
+			value := latexEscape(loc.FormatAmount(c.value, string(c.currency)))
+			switch {
+			case row.received:
+			case report.isOriginal(c.period, c.currency):
+				value = `\textbf{` + value + `}*`
+			case report.isManual(c.currency):
+				value = `\textit{` + value + `}\dag`
+			}
+			sb.WriteString(" & " + value)
+		}
+		sb.WriteString(" \\\\\n")
+	}
+	sb.WriteString("\\hline\n\\end{tabular}\n\n")
+
+	amount := loc.FormatAmount(report.Input.Amount, string(report.Input.Currency)) + " " + string(report.Input.Currency)
+	sb.WriteString(`\noindent * Original input: \textbf{` +
+		latexEscape(amount+"/"+strings.ToLower(string(report.Input.Period))) + `}\\` + "\n")
+	if manual := manualCurrencies(report.RateInfo); len(manual) > 0 {
+		sb.WriteString(`\dag\ Manual rate: ` + latexEscape(strings.Join(manual, ", ")) + `\\` + "\n")
+	}
+	if report.Received != nil {
+		sb.WriteString(latexEscape(feeNote(report.Fees, report.Input.Currency)) + `\\` + "\n")
+	}
+	for _, line := range sourceLines(report.RateInfo) {
+		sb.WriteString(latexEscape(line[0]) + ": " + latexEscape(line[1]) + `\\` + "\n")
+	}
+	if report.RateInfo != nil && report.RateInfo.Stale {
+		sb.WriteString(`\textbf{` + latexEscape(staleNote(report.RateInfo)) + `}\\` + "\n")
+	}
+
+	if report.Verbose && report.RateInfo != nil {
+		sb.WriteString("\n\\begin{tabular}{lrl}\n\\hline\n")
+		sb.WriteString(`\textbf{Currency} & \textbf{Rate} & \textbf{Kind} \\` + "\n\\hline\n")
+		for _, currency := range sortedCurrencies(report.Rates) {
+			sb.WriteString(fmt.Sprintf("%s & %s & %s \\\\\n", latexEscape(currency),
+				latexEscape(loc.FormatNumber(report.Rates[currency], 4)),
+				latexEscape(string(report.RateInfo.Kinds[currency]))))
+		}
+		sb.WriteString("\\hline\n\\end{tabular}\n")
+	}
+
+	return sb.String(), nil
+}
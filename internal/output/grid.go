@@ -0,0 +1,190 @@
+package output
+
+import "strings"
+
+type alignment int
+
+const (
+	alignLeft alignment = iota
+	alignRight
+	alignCenter
+)
+
+const minColumnWidth = 3
+
+// cell is a grid cell; color is applied after padding so that escape
+// sequences never count towards the column width.
+type cell struct {
+	text  string
+	color string
+}
+
+func plainCells(texts ...string) []cell {
+	cells := make([]cell, len(texts))
+	for i, text := range texts {
+		cells[i] = cell{text: text}
+	}
+	return cells
+}
+
+// grid is a box-drawn table whose columns are sized to their content.
+// Right-aligned columns hold numbers and are never truncated.
+type grid struct {
+	header []string
+	rows   [][]cell
+	align  []alignment
+}
+
+// render draws the grid. When maxWidth is positive and the grid is wider,
+// its columns are wrapped into several grids that each repeat the first
+// column. A grid that is still too wide has its widest text columns
+// narrowed, truncating their cells.
+func (g *grid) render(theme Theme, maxWidth int) string {
+	widths := make([]int, len(g.header))
+	for i, h := range g.header {
+		widths[i] = displayWidth(h)
+	}
+	for _, row := range g.rows {
+		for i, c := range row {
+			widths[i] = max(widths[i], displayWidth(c.text))
+		}
+	}
+
+	var sb strings.Builder
+	for i, columns := range g.wrap(widths, maxWidth) {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sub := make([]int, len(columns))
+		for j, column := range columns {
+			sub[j] = widths[column]
+		}
+		if maxWidth > 0 {
+			g.narrow(columns, sub, maxWidth)
+		}
+		g.draw(&sb, theme, columns, sub)
+	}
+	return sb.String()
+}
+
+// wrap splits the columns into groups that fit maxWidth, each starting with
+// the first column. A column too wide to share a group gets one of its own.
+func (g *grid) wrap(widths []int, maxWidth int) [][]int {
+	all := make([]int, len(widths))
+	for i := range all {
+		all[i] = i
+	}
+	if maxWidth <= 0 || len(widths) < 2 || tableWidth(widths) <= maxWidth {
+		return [][]int{all}
+	}
+
+	var groups [][]int
+	group, width := []int{0}, tableWidth(widths[:1])
+	for _, column := range all[1:] {
+		if len(group) > 1 && width+widths[column]+3 > maxWidth {
+			groups = append(groups, group)
+			group, width = []int{0}, tableWidth(widths[:1])
+		}
+		group = append(group, column)
+		width += widths[column] + 3
+	}
+	return append(groups, group)
+}
+
+// narrow shrinks the widest text columns of a group, one column at a time,
+// until the group fits maxWidth or no text column can shrink further.
+func (g *grid) narrow(columns, widths []int, maxWidth int) {
+	for tableWidth(widths) > maxWidth {
+		widest := -1
+		for i, w := range widths {
+			if g.alignOf(columns[i]) == alignRight || w <= minColumnWidth {
+				continue
+			}
+			if widest < 0 || w > widths[widest] {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			return
+		}
+		widths[widest]--
+	}
+}
+
+// draw writes the given columns of the grid with widths.
+func (g *grid) draw(sb *strings.Builder, theme Theme, columns, widths []int) {
+	pick := func(cells []cell) []cell {
+		picked := make([]cell, len(columns))
+		for i, column := range columns {
+			if column < len(cells) {
+				picked[i] = cells[column]
+			}
+		}
+		return picked
+	}
+	alignOf := func(i int) alignment { return g.alignOf(columns[i]) }
+
+	b := theme.border
+	writeBorder(sb, b, widths, b.topLeft, b.topMiddle, b.topRight)
+	g.writeRow(sb, theme, widths, pick(plainCells(g.header...)), func(int) alignment { return alignCenter })
+	writeBorder(sb, b, widths, b.middleLeft, b.middleMiddle, b.middleRight)
+	for _, row := range g.rows {
+		g.writeRow(sb, theme, widths, pick(row), alignOf)
+	}
+	writeBorder(sb, b, widths, b.bottomLeft, b.bottomMiddle, b.bottomRight)
+}
+
+func (g *grid) alignOf(column int) alignment {
+	if column < len(g.align) {
+		return g.align[column]
+	}
+	return alignLeft
+}
+
+func writeBorder(sb *strings.Builder, b borderGlyphs, widths []int, left, middle, right string) {
+	sb.WriteString(left)
+	for i, w := range widths {
+		if i > 0 {
+			sb.WriteString(middle)
+		}
+		sb.WriteString(strings.Repeat(b.horizontal, w+2))
+	}
+	sb.WriteString(right)
+	sb.WriteString("\n")
+}
+
+func (g *grid) writeRow(sb *strings.Builder, theme Theme, widths []int, cells []cell, alignOf func(int) alignment) {
+	sb.WriteString(theme.border.vertical)
+	for i, w := range widths {
+		var c cell
+		if i < len(cells) {
+			c = cells[i]
+		}
+
+		var padded string
+		switch alignOf(i) {
+		case alignRight:
+			padded = padLeft(c.text, w)
+		case alignCenter:
+			padded = padCenter(c.text, w)
+		default:
+			padded = padRight(c.text, w)
+		}
+
+		sb.WriteString(" ")
+		sb.WriteString(theme.paint(c.color, padded))
+		sb.WriteString(" ")
+		sb.WriteString(theme.border.vertical)
+	}
+	sb.WriteString("\n")
+}
+
+// tableWidth returns the rendered width of a grid with the given column
+// widths: each column has a space either side and a border on its right.
+func tableWidth(widths []int) int {
+	total := 1
+	for _, w := range widths {
+		total += w + 3
+	}
+	return total
+}
@@ -0,0 +1,51 @@
+package output
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"salary-calc/internal/exchangerate"
+)
+
+// FormatHistory renders a dated rate series with its min, max and mean.
+func FormatHistory(pair string, points []exchangerate.Point, opts Options) string {
+	if len(points) == 0 {
+		return fmt.Sprintf("No history for %s in the selected range.\n", pair)
+	}
+
+	var sb strings.Builder
+	loc := opts.Locale
+
+	g := &grid{
+		header: []string{"Date", pair},
+		align:  []alignment{alignLeft, alignRight},
+	}
+	for _, p := range points {
+		g.rows = append(g.rows, plainCells(p.Date.Format("2006-01-02"), loc.FormatNumber(p.Rate, 4)))
+	}
+	sb.WriteString(g.render(opts.Theme, terminalWidth()))
+
+	lo, hi, mean := seriesStats(points)
+	first, last := points[0], points[len(points)-1]
+
+	sb.WriteString(fmt.Sprintf("\nDays: %d (%s to %s)\n", len(points),
+		first.Date.Format("2006-01-02"), last.Date.Format("2006-01-02")))
+	sb.WriteString(fmt.Sprintf("Min: %s\n", loc.FormatNumber(lo, 4)))
+	sb.WriteString(fmt.Sprintf("Max: %s\n", loc.FormatNumber(hi, 4)))
+	sb.WriteString(fmt.Sprintf("Mean: %s\n", loc.FormatNumber(mean, 4)))
+	sb.WriteString(fmt.Sprintf("Change: %s%%\n", opts.formatSigned((last.Rate-first.Rate)/first.Rate*100)))
+
+	return sb.String()
+}
+
+func seriesStats(points []exchangerate.Point) (lo, hi, mean float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	var sum float64
+	for _, p := range points {
+		lo = math.Min(lo, p.Rate)
+		hi = math.Max(hi, p.Rate)
+		sum += p.Rate
+	}
+	return lo, hi, sum / float64(len(points))
+}
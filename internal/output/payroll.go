@@ -0,0 +1,137 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"salary-calc/internal/converter"
+	"salary-calc/internal/payroll"
+)
+
+// FormatPayroll renders the team totals for every period, then the
+// headcount, totals, mean and median per group for one period.
+func FormatPayroll(summary *payroll.Summary, period converter.Period, opts Options) string {
+	loc := opts.Locale
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Payroll: %d rows, headcount %s\n\n", summary.Rows, formatHeadcount(summary.All.Headcount, opts)))
+
+	totals := &grid{header: []string{"Total"}, align: []alignment{alignLeft}}
+	for _, currency := range summary.Currencies {
+		totals.header = append(totals.header, string(currency))
+		totals.align = append(totals.align, alignRight)
+	}
+	for _, p := range converter.ValidPeriods {
+		row := []cell{{text: string(p)}}
+		for _, currency := range summary.Currencies {
+			row = append(row, cell{text: loc.FormatAmount(summary.All.Total[p][currency], string(currency))})
+		}
+		totals.rows = append(totals.rows, row)
+
+		if summary.HasOverhead {
+			loaded := []cell{{text: "loaded", color: ansiDim}}
+			for _, currency := range summary.Currencies {
+				loaded = append(loaded, cell{text: loc.FormatAmount(summary.All.Loaded[p][currency], string(currency)), color: ansiDim})
+			}
+			totals.rows = append(totals.rows, loaded)
+		}
+	}
+	sb.WriteString(totals.render(opts.Theme, terminalWidth()))
+
+	label := "Team"
+	if summary.GroupBy != "" {
+		label = summary.GroupBy
+		sb.WriteString(fmt.Sprintf("\nPer %s, by %s:\n", strings.ToLower(string(period)), summary.GroupBy))
+	} else {
+		sb.WriteString(fmt.Sprintf("\nPer %s:\n", strings.ToLower(string(period))))
+	}
+
+	groups := &grid{
+		header: []string{label, "Headcount"},
+		align:  []alignment{alignLeft, alignRight},
+	}
+	for _, currency := range summary.Currencies {
+		groups.header = append(groups.header, "Total "+string(currency))
+		if summary.HasOverhead {
+			groups.header = append(groups.header, "Loaded "+string(currency))
+		}
+		groups.header = append(groups.header, "Mean "+string(currency), "Median "+string(currency))
+	}
+	for _, g := range summary.Groups {
+		groups.rows = append(groups.rows, payrollRow(g.Name, g.Stats, summary, period, opts))
+	}
+	groups.rows = append(groups.rows, payrollRow("All", summary.All, summary, period, opts))
+	for range groups.header[2:] {
+		groups.align = append(groups.align, alignRight)
+	}
+	sb.WriteString(groups.render(opts.Theme, terminalWidth()))
+
+	return sb.String()
+}
+
+func payrollRow(name string, stats payroll.Stats, summary *payroll.Summary, period converter.Period, opts Options) []cell {
+	loc := opts.Locale
+	row := plainCells(name, formatHeadcount(stats.Headcount, opts))
+	for _, currency := range summary.Currencies {
+		c := string(currency)
+		row = append(row, cell{text: loc.FormatAmount(stats.Total[period][currency], c)})
+		if summary.HasOverhead {
+			row = append(row, cell{text: loc.FormatAmount(stats.Loaded[period][currency], c)})
+		}
+		row = append(row,
+			cell{text: loc.FormatAmount(stats.Mean[period][currency], c)},
+			cell{text: loc.FormatAmount(stats.Median[period][currency], c)})
+	}
+	return row
+}
+
+// formatHeadcount shows whole headcounts without decimals and fractional
+// (part-time) ones with two.
+func formatHeadcount(headcount float64, opts Options) string {
+	if headcount == math.Trunc(headcount) {
+		return opts.Locale.FormatNumber(headcount, 0)
+	}
+	return opts.Locale.FormatNumber(headcount, 2)
+}
+
+// FormatPayrollCSV writes one line per group, period and currency, with the
+// whole team as group "All".
+func FormatPayrollCSV(summary *payroll.Summary) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	groupColumn := "group"
+	if summary.GroupBy != "" {
+		groupColumn = summary.GroupBy
+	}
+	_ = w.Write([]string{groupColumn, "period", "currency", "headcount", "total", "loaded", "mean", "median"})
+
+	groups := append(append([]payroll.Group(nil), summary.Groups...), payroll.Group{Name: "All", Stats: summary.All})
+	for _, g := range groups {
+		for _, period := range converter.ValidPeriods {
+			for _, currency := range summary.Currencies {
+				_ = w.Write([]string{
+					g.Name,
+					strings.ToLower(string(period)),
+					string(currency),
+					strconv.FormatFloat(g.Headcount, 'f', -1, 64),
+					formatCSVAmount(g.Total[period][currency]),
+					formatCSVAmount(g.Loaded[period][currency]),
+					formatCSVAmount(g.Mean[period][currency]),
+					formatCSVAmount(g.Median[period][currency]),
+				})
+			}
+		}
+	}
+
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+func formatCSVAmount(n float64) string {
+	return strconv.FormatFloat(n, 'f', 2, 64)
+}
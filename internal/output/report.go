@@ -0,0 +1,203 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"salary-calc/internal/converter"
+	"salary-calc/internal/exchangerate"
+)
+
+// Report is a conversion result together with everything needed to present it.
+type Report struct {
+	Input    converter.Input
+	Results  map[converter.Period]map[converter.Currency]float64
+	Received map[converter.Period]map[converter.Currency]float64
+	Fees     converter.Fees
+	RateInfo *exchangerate.RateInfo
+	Rates    map[string]float64
+	Verbose  bool
+
+	// Periods and Currencies select and order what is shown; nil shows all.
+	// Transpose puts currencies in rows and periods in columns.
+	Periods    []converter.Period
+	Currencies []converter.Currency
+	Transpose  bool
+}
+
+// Renderer turns a report into text in one output format.
+type Renderer interface {
+	Render(report *Report) (string, error)
+}
+
+// Formats lists the built-in output formats.
+var Formats = []string{"table", "compact", "markdown", "html", "latex"}
+
+// NewRenderer returns the renderer for a built-in output format.
+func NewRenderer(format string, opts Options) (Renderer, error) {
+	switch strings.ToLower(format) {
+	case "table", "":
+		return newTemplateRenderer("table", tableTemplate, opts)
+	case "compact":
+		return newTemplateRenderer("compact", compactTemplate, opts)
+	case "markdown", "md":
+		return &markdownRenderer{opts: opts}, nil
+	case "html":
+		return &htmlRenderer{opts: opts}, nil
+	case "latex", "tex":
+		return &latexRenderer{opts: opts}, nil
+	}
+	return nil, fmt.Errorf("invalid output format: %s (supported: %s)", format, strings.Join(Formats, ", "))
+}
+
+func (r *Report) periods() []converter.Period {
+	if r.Periods == nil {
+		return converter.ValidPeriods
+	}
+	return r.Periods
+}
+
+func (r *Report) currencies() []converter.Currency {
+	if r.Currencies == nil {
+		return converter.ValidCurrencies
+	}
+	return r.Currencies
+}
+
+// layoutCell is one amount of the results grid.
+type layoutCell struct {
+	period   converter.Period
+	currency converter.Currency
+	value    float64
+}
+
+// layoutRow is one row of the results grid. Received rows follow the row
+// they belong to and hold amounts after fees.
+type layoutRow struct {
+	label    string
+	received bool
+	cells    []layoutCell
+}
+
+// layout arranges the selected results into a header and rows, transposed
+// when requested, so that every format lays out the grid the same way.
+func (r *Report) layout() ([]string, []layoutRow) {
+	periods, currencies := r.periods(), r.currencies()
+
+	header := []string{"Period"}
+	labels := make([]string, 0, len(periods))
+	for _, period := range periods {
+		labels = append(labels, string(period))
+	}
+	for _, currency := range currencies {
+		header = append(header, string(currency))
+	}
+	if r.Transpose {
+		header, labels = append([]string{"Currency"}, labels...), header[1:]
+	}
+
+	cellAt := func(values map[converter.Period]map[converter.Currency]float64, i, j int) layoutCell {
+		if r.Transpose {
+			i, j = j, i
+		}
+		period, currency := periods[i], currencies[j]
+		return layoutCell{period: period, currency: currency, value: values[period][currency]}
+	}
+
+	var rows []layoutRow
+	for i, label := range labels {
+		row := layoutRow{label: label}
+		for j := range header[1:] {
+			row.cells = append(row.cells, cellAt(r.Results, i, j))
+		}
+		rows = append(rows, row)
+
+		if r.Received != nil {
+			recv := layoutRow{label: "recv", received: true}
+			for j := range header[1:] {
+				recv.cells = append(recv.cells, cellAt(r.Received, i, j))
+			}
+			rows = append(rows, recv)
+		}
+	}
+	return header, rows
+}
+
+func (r *Report) isOriginal(period converter.Period, currency converter.Currency) bool {
+	return period == r.Input.Period && currency == r.Input.Currency
+}
+
+func (r *Report) isManual(currency converter.Currency) bool {
+	return isManual(r.RateInfo, currency)
+}
+
+func isManual(rateInfo *exchangerate.RateInfo, currency converter.Currency) bool {
+	return rateInfo != nil && rateInfo.Kinds[string(currency)] == exchangerate.RateManual
+}
+
+func manualCurrencies(rateInfo *exchangerate.RateInfo) []string {
+	var manual []string
+	for _, currency := range converter.ValidCurrencies {
+		if isManual(rateInfo, currency) {
+			manual = append(manual, string(currency))
+		}
+	}
+	return manual
+}
+
+// feeNote explains the recv rows, listing the fee model per currency.
+func feeNote(fees converter.Fees, original converter.Currency) string {
+	note := "recv: amount received after fees"
+
+	var models []string
+	for _, currency := range converter.ValidCurrencies {
+		if fee, ok := fees[currency]; ok && currency != original {
+			models = append(models, fmt.Sprintf("%s %s", currency, fee))
+		}
+	}
+	if len(models) > 0 {
+		note += " (" + strings.Join(models, ", ") + ")"
+	}
+	return note
+}
+
+// sourceLines returns the rate footer as label/value pairs.
+func sourceLines(rateInfo *exchangerate.RateInfo) [][2]string {
+	if rateInfo == nil {
+		return nil
+	}
+
+	lines := [][2]string{{"Rate source", rateInfo.Source}}
+	if isPeriodMode(rateInfo) {
+		lines = append(lines, [2]string{"Rate mode", formatRateWindow(rateInfo)})
+	} else {
+		lines = append(lines, [2]string{"Last updated", rateInfo.Timestamp.Format("2006-01-02 15:04:05 UTC")})
+		// Rates read from a file are never cached.
+		if !rateInfo.ExpiresAt.IsZero() {
+			expires := rateInfo.ExpiresAt.Format("2006-01-02 15:04:05 UTC")
+			if rateInfo.Revalidating {
+				expires += " (refreshing in background)"
+			}
+			lines = append(lines, [2]string{"Cache expires", expires})
+		}
+	}
+	return lines
+}
+
+func sortedCurrencies(rates map[string]float64) []string {
+	currencies := make([]string, 0, len(rates))
+	for currency := range rates {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+func staleNote(rateInfo *exchangerate.RateInfo) string {
+	if rateInfo.ExpiresAt.IsZero() {
+		return "Rates are stale: they date from " + rateInfo.Timestamp.Format("2006-01-02")
+	}
+	return "Rates are stale: fresh rates could not be fetched, using rates from " +
+		rateInfo.Timestamp.Format("2006-01-02 15:04 UTC")
+}
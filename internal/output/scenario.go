@@ -0,0 +1,129 @@
+package output
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"salary-calc/internal/converter"
+	"salary-calc/internal/scenario"
+)
+
+// FormatScenario renders the budget cost for every period under each rate
+// shock, the sensitivity per currency and, when computed, the volatility
+// range.
+func FormatScenario(report *scenario.Report, opts Options) string {
+	loc := opts.Locale
+	budget := string(report.Budget)
+	period := strings.ToLower(string(report.Period))
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Cost in %s when foreign currencies move against %s:\n", budget, budget))
+
+	var baseline float64
+	for _, row := range report.Rows {
+		if row.Shock == 0 {
+			baseline = row.Cost[report.Period]
+		}
+	}
+
+	matrix := &grid{header: []string{"Shock"}, align: []alignment{alignRight}}
+	for _, p := range converter.ValidPeriods {
+		matrix.header = append(matrix.header, string(p))
+		matrix.align = append(matrix.align, alignRight)
+	}
+	matrix.header = append(matrix.header, "Change/"+period)
+	matrix.align = append(matrix.align, alignRight)
+
+	for _, row := range report.Rows {
+		var color string
+		switch {
+		case row.Shock == 0:
+			color = ansiDim
+		case row.Shock > 0:
+			color = ansiRed
+		default:
+			color = ansiGreen
+		}
+
+		cells := []cell{{text: signedPercent(row.Shock, opts), color: color}}
+		for _, p := range converter.ValidPeriods {
+			cells = append(cells, cell{text: loc.FormatAmount(row.Cost[p], budget)})
+		}
+		change := row.Cost[report.Period] - baseline
+		changeText := loc.FormatAmount(change, budget)
+		if change >= 0 {
+			changeText = "+" + changeText
+		}
+		cells = append(cells, cell{text: changeText, color: color})
+		matrix.rows = append(matrix.rows, cells)
+	}
+	sb.WriteString(matrix.render(opts.Theme, terminalWidth()))
+
+	if len(report.Exposures) == 0 {
+		sb.WriteString(fmt.Sprintf("\nNo foreign currency exposure: every salary is paid in %s.\n", budget))
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("\nSensitivity per %s:\n", period))
+	sensitivity := &grid{
+		header: []string{"Currency", "Paid", "Rate", "Cost " + budget, "Per 1% " + budget},
+		align:  []alignment{alignLeft, alignRight, alignRight, alignRight, alignRight},
+	}
+	var cost, perPercent float64
+	for _, e := range report.Exposures {
+		c := string(e.Currency)
+		sensitivity.rows = append(sensitivity.rows, plainCells(
+			c,
+			loc.FormatAmount(e.Amount, c),
+			loc.FormatNumber(e.Rate, 4),
+			loc.FormatAmount(e.Cost, budget),
+			loc.FormatAmount(e.PerPercent, budget)))
+		cost += e.Cost
+		perPercent += e.PerPercent
+	}
+	if len(report.Exposures) > 1 {
+		sensitivity.rows = append(sensitivity.rows, plainCells(
+			"All", "", "",
+			loc.FormatAmount(cost, budget),
+			loc.FormatAmount(perPercent, budget)))
+	}
+	sb.WriteString(sensitivity.render(opts.Theme, terminalWidth()))
+
+	if len(report.Ranges) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("\n95%% range over %s, from %s of history:\n", report.Horizon, report.Lookback))
+	ranges := &grid{
+		header: []string{"Currency", "Days", "Daily vol", "Rate low", "Rate high", "Cost low " + budget, "Cost high " + budget},
+		align:  []alignment{alignLeft, alignRight, alignRight, alignRight, alignRight, alignRight, alignRight},
+	}
+	for _, r := range report.Ranges {
+		ranges.rows = append(ranges.rows, plainCells(
+			string(r.Currency),
+			loc.FormatNumber(float64(r.Samples), 0),
+			loc.FormatNumber(r.DailyVolatility, 2)+"%",
+			loc.FormatNumber(r.Low, 4),
+			loc.FormatNumber(r.High, 4),
+			loc.FormatAmount(r.CostLow, budget),
+			loc.FormatAmount(r.CostHigh, budget)))
+	}
+	sb.WriteString(ranges.render(opts.Theme, terminalWidth()))
+
+	return sb.String()
+}
+
+// signedPercent formats a shock such as "+5%" or "-2.5%", without color so
+// that it can be placed in a grid cell.
+func signedPercent(pct float64, opts Options) string {
+	decimals := 2
+	if pct == math.Trunc(pct) {
+		decimals = 0
+	}
+	s := opts.Locale.FormatNumber(pct, decimals)
+	if pct > 0 {
+		s = "+" + s
+	}
+	return s + "%"
+}
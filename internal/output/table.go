@@ -2,174 +2,190 @@ package output
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
-	"salary-calc/internal/converter"
 	"salary-calc/internal/exchangerate"
 )
 
-type TableFormatter struct {
-	originalAmount   float64
-	originalPeriod   converter.Period
-	originalCurrency converter.Currency
-	rateInfo         *exchangerate.RateInfo
-}
-
-func NewTableFormatter(amount float64, period converter.Period, currency converter.Currency, rateInfo *exchangerate.RateInfo) *TableFormatter {
-	return &TableFormatter{
-		originalAmount:   amount,
-		originalPeriod:   period,
-		originalCurrency: currency,
-		rateInfo:         rateInfo,
+// formatTable draws the results grid with a recv row under each row when
+// fees apply.
+func formatTable(data *TemplateData, opts Options) string {
+	loc := opts.Locale
+	theme := opts.Theme
+	markers := theme.border
+
+	header, rows := data.layout()
+	g := &grid{header: header, align: []alignment{alignLeft}}
+	for range header[1:] {
+		g.align = append(g.align, alignRight)
 	}
-}
 
-func (tf *TableFormatter) Format(results map[converter.Period]map[converter.Currency]float64) string {
-	var sb strings.Builder
-
-	periodWidth := 8
-	currencyWidth := 13
-
-	sb.WriteString("┌")
-	sb.WriteString(strings.Repeat("─", periodWidth))
-	for range converter.ValidCurrencies {
-		sb.WriteString("┬")
-		sb.WriteString(strings.Repeat("─", currencyWidth))
-	}
-	sb.WriteString("┐\n")
-
-	// Header row
-	sb.WriteString("│")
-	sb.WriteString(padCenter("Period", periodWidth))
-	for _, currency := range converter.ValidCurrencies {
-		sb.WriteString("│")
-		sb.WriteString(padCenter(string(currency), currencyWidth))
-	}
-	sb.WriteString("│\n")
-
-	sb.WriteString("├")
-	sb.WriteString(strings.Repeat("─", periodWidth))
-	for range converter.ValidCurrencies {
-		sb.WriteString("┼")
-		sb.WriteString(strings.Repeat("─", currencyWidth))
-	}
-	sb.WriteString("┤\n")
-
-	// Data rows
-	for _, period := range converter.ValidPeriods {
-		sb.WriteString("│")
-		sb.WriteString(padLeft(string(period), periodWidth))
-		for _, currency := range converter.ValidCurrencies {
-			sb.WriteString("│")
-			value := results[period][currency]
-			formattedValue := formatNumber(value)
-			isOriginal := period == tf.originalPeriod && currency == tf.originalCurrency
-			if isOriginal {
-				formattedValue = formattedValue + " ⭐"
+	// Markers follow the amount, so amounts without one are padded to keep
+	// the digits of a column aligned.
+	markerWidths := make([]int, len(header))
+	marks := make([][]string, len(rows))
+	for i, row := range rows {
+		cells := []cell{{text: row.label}}
+		if row.received {
+			cells[0].color = ansiDim
+		}
+		marks[i] = make([]string, len(header))
+		for j, lc := range row.cells {
+			c := cell{text: loc.FormatAmount(lc.value, string(lc.currency))}
+			switch {
+			case row.received:
+				c.color = ansiDim
+			case data.isOriginal(lc.period, lc.currency):
+				marks[i][j+1] = " " + markers.originalMarker
+				c.color = ansiGreen
+			case data.isManual(lc.currency):
+				marks[i][j+1] = " " + markers.manualMarker
+				c.color = ansiMagenta
+			case data.Stale():
+				c.color = ansiYellow
 			}
-			sb.WriteString(padRight(formattedValue, currencyWidth))
+			markerWidths[j+1] = max(markerWidths[j+1], displayWidth(marks[i][j+1]))
+			cells = append(cells, c)
 		}
-		sb.WriteString("│\n")
+		g.rows = append(g.rows, cells)
 	}
-
-	// Footer
-	sb.WriteString("└")
-	sb.WriteString(strings.Repeat("─", periodWidth))
-	for range converter.ValidCurrencies {
-		sb.WriteString("┴")
-		sb.WriteString(strings.Repeat("─", currencyWidth))
-	}
-	sb.WriteString("┘\n")
-
-	sb.WriteString("\n⭐ Original input: ")
-	sb.WriteString(formatNumber(tf.originalAmount))
-	sb.WriteString(" ")
-	sb.WriteString(string(tf.originalCurrency))
-	sb.WriteString("/")
-	sb.WriteString(strings.ToLower(string(tf.originalPeriod)))
-	sb.WriteString("\n")
-
-	if tf.rateInfo != nil {
-		sb.WriteString("\nRate source: ")
-		sb.WriteString(tf.rateInfo.Source)
-		sb.WriteString("\nLast updated: ")
-		sb.WriteString(tf.rateInfo.Timestamp.Format("2006-01-02 15:04:05 UTC"))
-		sb.WriteString("\nCache expires: ")
-		sb.WriteString(tf.rateInfo.ExpiresAt.Format("2006-01-02 15:04:05 UTC"))
-		sb.WriteString("\n")
+	for i, cells := range g.rows {
+		for j := 1; j < len(cells); j++ {
+			mark := marks[i][j]
+			cells[j].text += mark + strings.Repeat(" ", markerWidths[j]-displayWidth(mark))
+		}
 	}
 
-	return sb.String()
+	return g.render(theme, terminalWidth())
 }
 
-func padLeft(s string, width int) string {
-	if len(s) >= width {
-		return s[:width]
-	}
-	return strings.Repeat(" ", width-len(s)) + s
+func isPeriodMode(rateInfo *exchangerate.RateInfo) bool {
+	return rateInfo.Mode != "" && rateInfo.Mode != exchangerate.RateSpot
 }
 
-func padRight(s string, width int) string {
-	if len(s) >= width {
-		return s[:width]
+func formatRateWindow(rateInfo *exchangerate.RateInfo) string {
+	start := rateInfo.WindowStart.Format("2006-01-02")
+	end := rateInfo.WindowEnd.Format("2006-01-02")
+	if rateInfo.Mode == exchangerate.RatePeriodEnd {
+		return fmt.Sprintf("%s (rate of %s)", rateInfo.Mode, end)
 	}
-	return s + strings.Repeat(" ", width-len(s))
-}
-
-func padCenter(s string, width int) string {
-	if len(s) >= width {
-		return s[:width]
+	window := fmt.Sprintf("%s (%s to %s, %d days)", rateInfo.Mode, start, end, rateInfo.Samples)
+	if months := rateInfo.Months; len(months) > 0 {
+		window += fmt.Sprintf("; Year at the monthly averages of %s to %s", months[0].Month, months[len(months)-1].Month)
 	}
-	padding := width - len(s)
-	left := padding / 2
-	right := padding - left
-	return strings.Repeat(" ", left) + s + strings.Repeat(" ", right)
+	return window
 }
 
-func formatNumber(n float64) string {
-	formatted := fmt.Sprintf("%.2f", n)
+func FormatVerbose(rateInfo *exchangerate.RateInfo, rates map[string]float64, opts Options) string {
+	if rateInfo == nil {
+		return ""
+	}
+	loc := opts.Locale
 
-	parts := strings.Split(formatted, ".")
-	intPart := parts[0]
+	var sb strings.Builder
+	sb.WriteString("\n--- Exchange Rate Details ---\n")
+	if rateInfo.Stale {
+		sb.WriteString(opts.Theme.paint(ansiYellow, fmt.Sprintf("Source: %s\n", rateInfo.Source)))
+	} else {
+		sb.WriteString(fmt.Sprintf("Source: %s\n", rateInfo.Source))
+	}
+	sb.WriteString(fmt.Sprintf("Fetched at: %s\n", rateInfo.Timestamp.Format(time.RFC3339)))
+	if rateInfo.EffectiveDate != "" {
+		sb.WriteString(fmt.Sprintf("Effective date: %s\n", rateInfo.EffectiveDate))
+	}
+	if isPeriodMode(rateInfo) {
+		sb.WriteString(fmt.Sprintf("Rate mode: %s\n", formatRateWindow(rateInfo)))
+	} else if !rateInfo.ExpiresAt.IsZero() {
+		sb.WriteString(fmt.Sprintf("Expires at: %s\n", rateInfo.ExpiresAt.Format(time.RFC3339)))
+	}
+	if rateInfo.Base != "" {
+		sb.WriteString(fmt.Sprintf("Canonical base: %s\n", rateInfo.Base))
+	}
+	if rateInfo.PayloadDigest != "" {
+		sb.WriteString(fmt.Sprintf("Payload digest: %s\n", rateInfo.PayloadDigest))
+	}
+	sb.WriteString(formatQuota(rateInfo.Quota))
+	sb.WriteString("\nCurrent rates:\n")
 
-	if len(intPart) > 3 {
-		var result strings.Builder
-		start := len(intPart) % 3
-		if start > 0 {
-			result.WriteString(intPart[:start])
-			if start < len(intPart) {
-				result.WriteString(",")
+	for _, currency := range sortedCurrencies(rates) {
+		line := fmt.Sprintf("  %s: %s", currency, loc.FormatNumber(rates[currency], 4))
+		if kind, ok := rateInfo.Kinds[currency]; ok {
+			line += fmt.Sprintf(" (%s)", kind)
+			if kind == exchangerate.RateManual {
+				line = opts.Theme.paint(ansiMagenta, line)
 			}
 		}
-		for i := start; i < len(intPart); i += 3 {
-			if i > start {
-				result.WriteString(",")
-			}
-			result.WriteString(intPart[i : i+3])
+		bid, okBid := rateInfo.Bid[currency]
+		ask, okAsk := rateInfo.Ask[currency]
+		if okBid && okAsk {
+			line += fmt.Sprintf(" bid %s / ask %s", loc.FormatNumber(bid, 4), loc.FormatNumber(ask, 4))
 		}
-		intPart = result.String()
+		sb.WriteString(line + "\n")
 	}
 
-	if len(parts) > 1 {
-		return intPart + "." + parts[1]
+	if rateInfo.Consensus != nil {
+		sb.WriteString(formatConsensus(rateInfo.Consensus, opts))
 	}
-	return intPart
+	return sb.String()
 }
 
-func FormatVerbose(rateInfo *exchangerate.RateInfo, rates map[string]float64) string {
-	if rateInfo == nil {
-		return ""
+// formatQuota lists the provider's rate limit and usage headers.
+func formatQuota(quota map[string]string) string {
+	var sb strings.Builder
+	for _, name := range exchangerate.QuotaNames(quota) {
+		sb.WriteString(fmt.Sprintf("Quota %s: %s\n", name, quota[name]))
 	}
+	return sb.String()
+}
 
+func formatConsensus(report *exchangerate.ConsensusReport, opts Options) string {
+	loc := opts.Locale
 	var sb strings.Builder
-	sb.WriteString("\n--- Exchange Rate Details ---\n")
-	sb.WriteString(fmt.Sprintf("Source: %s\n", rateInfo.Source))
-	sb.WriteString(fmt.Sprintf("Fetched at: %s\n", rateInfo.Timestamp.Format(time.RFC3339)))
-	sb.WriteString(fmt.Sprintf("Expires at: %s\n", rateInfo.ExpiresAt.Format(time.RFC3339)))
-	sb.WriteString("\nCurrent rates:\n")
-	for currency, rate := range rates {
-		sb.WriteString(fmt.Sprintf("  %s: %.4f\n", currency, rate))
+	sb.WriteString("\n--- Provider Consensus ---\n")
+	sb.WriteString(fmt.Sprintf("Providers: %s\n", strings.Join(report.Providers, ", ")))
+	if report.SingleProvider {
+		sb.WriteString(opts.Theme.paint(ansiYellow, "Warning: only one provider answered; the rates are not cross-checked.") + "\n")
+	}
+
+	failed := make([]string, 0, len(report.Failed))
+	for name := range report.Failed {
+		failed = append(failed, name)
+	}
+	sort.Strings(failed)
+	for _, name := range failed {
+		sb.WriteString(fmt.Sprintf("Failed: %s (%s)\n", name, report.Failed[name]))
+	}
+
+	sb.WriteString(fmt.Sprintf("\nSpread between providers (threshold %s%%):\n", loc.FormatNumber(report.Threshold, 2)))
+	currencies := make([]string, 0, len(report.Spread))
+	for currency := range report.Spread {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		sb.WriteString(fmt.Sprintf("  %s: %s%%\n", currency, loc.FormatNumber(report.Spread[currency], 4)))
+	}
+
+	if len(report.Deviations) == 0 {
+		sb.WriteString("\nAll providers agree within the threshold.\n")
+		return sb.String()
+	}
+
+	sb.WriteString("\nDeviating quotes:\n")
+	for _, d := range report.Deviations {
+		sb.WriteString(fmt.Sprintf("  %s %s: %s (median %s, %s%%)\n", d.Provider, d.Currency,
+			loc.FormatNumber(d.Rate, 4), loc.FormatNumber(d.Median, 4), opts.formatSigned(d.Percent)))
 	}
 	return sb.String()
 }
+
+// formatSigned formats a percentage change with its sign, red when negative.
+func (o Options) formatSigned(n float64) string {
+	s := o.Locale.FormatNumber(n, 2)
+	if n >= 0 {
+		s = "+" + s
+	}
+	return o.Theme.signed(n, s)
+}
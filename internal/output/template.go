@@ -0,0 +1,212 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"salary-calc/internal/converter"
+)
+
+//go:embed templates/table.tmpl
+var tableTemplate string
+
+//go:embed templates/compact.tmpl
+var compactTemplate string
+
+// TemplateData is the value templates are executed with. Besides the report
+// fields (.Input, .Results, .Received, .Fees, .Rates, .RateInfo, .Verbose,
+// .Transpose) it lists the periods and currencies to show, in display order.
+type TemplateData struct {
+	*Report
+	Periods    []converter.Period
+	Currencies []converter.Currency
+}
+
+// SourceLine is one label/value line of the rate source footer.
+type SourceLine struct {
+	Label string
+	Value string
+}
+
+func newTemplateData(report *Report) *TemplateData {
+	return &TemplateData{
+		Report:     report,
+		Periods:    report.periods(),
+		Currencies: report.currencies(),
+	}
+}
+
+// Amount returns the converted amount for a period and currency,
+// e.g. {{.Amount "Month" "PLN"}}.
+func (d *TemplateData) Amount(period converter.Period, currency converter.Currency) (float64, error) {
+	return lookup(d.Results, period, currency)
+}
+
+// ReceivedAmount returns the amount received after fees for a period and
+// currency. It fails when the report has no received amounts.
+func (d *TemplateData) ReceivedAmount(period converter.Period, currency converter.Currency) (float64, error) {
+	if d.Received == nil {
+		return 0, fmt.Errorf("no received amounts: set a fee or -received")
+	}
+	return lookup(d.Received, period, currency)
+}
+
+func lookup(results map[converter.Period]map[converter.Currency]float64, period converter.Period, currency converter.Currency) (float64, error) {
+	value, ok := results[period][currency]
+	if !ok {
+		return 0, fmt.Errorf("no result for %s in %s", period, currency)
+	}
+	return value, nil
+}
+
+// IsOriginal tells whether the cell holds the amount that was entered.
+func (d *TemplateData) IsOriginal(period converter.Period, currency converter.Currency) bool {
+	return d.isOriginal(period, currency)
+}
+
+// IsManual tells whether currency was converted at a pinned rate.
+func (d *TemplateData) IsManual(currency converter.Currency) bool {
+	return d.isManual(currency)
+}
+
+// Manual lists the currencies converted at pinned rates.
+func (d *TemplateData) Manual() []string {
+	return manualCurrencies(d.RateInfo)
+}
+
+// FeeNote explains the received amounts.
+func (d *TemplateData) FeeNote() string {
+	return feeNote(d.Fees, d.Input.Currency)
+}
+
+// Sources returns the rate source footer lines.
+func (d *TemplateData) Sources() []SourceLine {
+	var lines []SourceLine
+	for _, line := range sourceLines(d.RateInfo) {
+		lines = append(lines, SourceLine{Label: line[0], Value: line[1]})
+	}
+	return lines
+}
+
+// Stale tells whether expired rates were used because fetching failed.
+func (d *TemplateData) Stale() bool {
+	return d.RateInfo != nil && d.RateInfo.Stale
+}
+
+// StaleNote explains stale rates.
+func (d *TemplateData) StaleNote() string {
+	if !d.Stale() {
+		return ""
+	}
+	return staleNote(d.RateInfo)
+}
+
+var periodAbbreviations = map[converter.Period]string{
+	converter.PeriodHour:  "h",
+	converter.PeriodDay:   "d",
+	converter.PeriodMonth: "mo",
+	converter.PeriodYear:  "yr",
+}
+
+var templateColors = map[string]string{
+	"red":     ansiRed,
+	"green":   ansiGreen,
+	"yellow":  ansiYellow,
+	"magenta": ansiMagenta,
+	"dim":     ansiDim,
+}
+
+// templateFuncs returns the helpers available to templates. Amounts and
+// numbers follow the locale; paint and marker follow the theme.
+func templateFuncs(opts Options) template.FuncMap {
+	loc := opts.Locale
+	theme := opts.Theme
+
+	return template.FuncMap{
+		"money": func(amount float64, currency any) string {
+			return loc.FormatMoney(amount, fmt.Sprint(currency))
+		},
+		"amount": func(amount float64, currency any) string {
+			return loc.FormatAmount(amount, fmt.Sprint(currency))
+		},
+		"number": func(n float64, decimals int) string {
+			return loc.FormatNumber(n, decimals)
+		},
+		"signed": func(n float64) string {
+			return opts.formatSigned(n)
+		},
+		"period": func(period any) string {
+			return strings.ToLower(fmt.Sprint(period))
+		},
+		"per": func(period converter.Period) string {
+			return periodAbbreviations[period]
+		},
+		"date": func(t time.Time) string {
+			return t.Format("2006-01-02")
+		},
+		"datetime": func(t time.Time) string {
+			return t.Format("2006-01-02 15:04:05 UTC")
+		},
+		"upper": func(s any) string { return strings.ToUpper(fmt.Sprint(s)) },
+		"lower": func(s any) string { return strings.ToLower(fmt.Sprint(s)) },
+		"join":  strings.Join,
+		"paint": func(color, s string) (string, error) {
+			code, ok := templateColors[color]
+			if !ok {
+				return "", fmt.Errorf("unknown color: %s", color)
+			}
+			return theme.paint(code, s), nil
+		},
+		"marker": func(name string) (string, error) {
+			switch name {
+			case "original":
+				return theme.border.originalMarker, nil
+			case "manual":
+				return theme.border.manualMarker, nil
+			case "stale":
+				return theme.border.staleMarker, nil
+			}
+			return "", fmt.Errorf("unknown marker: %s", name)
+		},
+		"table": func(data *TemplateData) string {
+			return formatTable(data, opts)
+		},
+		"verbose": func(data *TemplateData) string {
+			return FormatVerbose(data.RateInfo, data.Rates, opts)
+		},
+	}
+}
+
+type templateRenderer struct {
+	tmpl *template.Template
+}
+
+// NewTemplateRenderer parses a text/template file to render reports with.
+func NewTemplateRenderer(path string, opts Options) (Renderer, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	return newTemplateRenderer(filepath.Base(path), string(text), opts)
+}
+
+func newTemplateRenderer(name, text string, opts Options) (Renderer, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs(opts)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return &templateRenderer{tmpl: tmpl}, nil
+}
+
+func (r *templateRenderer) Render(report *Report) (string, error) {
+	var sb strings.Builder
+	if err := r.tmpl.Execute(&sb, newTemplateData(report)); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,132 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"salary-calc/internal/locale"
+)
+
+// ColorMode controls when ANSI colors are emitted.
+type ColorMode string
+
+const (
+	ColorAuto   ColorMode = "auto"
+	ColorAlways ColorMode = "always"
+	ColorNever  ColorMode = "never"
+)
+
+func ParseColorMode(s string) (ColorMode, error) {
+	switch mode := ColorMode(strings.ToLower(s)); mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid color mode: %s (supported: auto, always, never)", s)
+}
+
+// BorderStyle selects the characters tables and charts are drawn with.
+type BorderStyle string
+
+const (
+	BorderUnicode BorderStyle = "unicode"
+	BorderASCII   BorderStyle = "ascii"
+)
+
+func ParseBorderStyle(s string) (BorderStyle, error) {
+	switch style := BorderStyle(strings.ToLower(s)); style {
+	case BorderUnicode, BorderASCII:
+		return style, nil
+	}
+	return "", fmt.Errorf("invalid border style: %s (supported: unicode, ascii)", s)
+}
+
+const (
+	ansiReset   = "\x1b[0m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[1;32m"
+	ansiYellow  = "\x1b[33m"
+	ansiMagenta = "\x1b[35m"
+	ansiDim     = "\x1b[2m"
+)
+
+type borderGlyphs struct {
+	horizontal, vertical                      string
+	topLeft, topMiddle, topRight              string
+	middleLeft, middleMiddle, middleRight     string
+	bottomLeft, bottomMiddle, bottomRight     string
+	originalMarker, manualMarker, staleMarker string
+}
+
+var unicodeBorder = borderGlyphs{
+	horizontal: "─", vertical: "│",
+	topLeft: "┌", topMiddle: "┬", topRight: "┐",
+	middleLeft: "├", middleMiddle: "┼", middleRight: "┤",
+	bottomLeft: "└", bottomMiddle: "┴", bottomRight: "┘",
+	originalMarker: "⭐", manualMarker: "✎", staleMarker: "⚠",
+}
+
+var asciiBorder = borderGlyphs{
+	horizontal: "-", vertical: "|",
+	topLeft: "+", topMiddle: "+", topRight: "+",
+	middleLeft: "+", middleMiddle: "+", middleRight: "+",
+	bottomLeft: "+", bottomMiddle: "+", bottomRight: "+",
+	originalMarker: "*", manualMarker: "~", staleMarker: "!",
+}
+
+// Theme holds the colors and glyphs output is drawn with.
+type Theme struct {
+	color  bool
+	ascii  bool
+	border borderGlyphs
+}
+
+// DefaultTheme draws Unicode borders without colors.
+var DefaultTheme = Theme{border: unicodeBorder}
+
+// NewTheme resolves the color mode against NO_COLOR and whether stdout is a
+// terminal. An explicit -color=always wins over NO_COLOR.
+func NewTheme(mode ColorMode, style BorderStyle) Theme {
+	theme := Theme{border: unicodeBorder}
+	if style == BorderASCII {
+		theme.ascii = true
+		theme.border = asciiBorder
+	}
+
+	switch mode {
+	case ColorAlways:
+		theme.color = true
+	case ColorAuto:
+		theme.color = os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" && isTerminal(os.Stdout)
+	}
+	return theme
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (t Theme) paint(code, s string) string {
+	if !t.color || code == "" || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// signed paints a delta red when it is negative.
+func (t Theme) signed(n float64, s string) string {
+	if n < 0 {
+		return t.paint(ansiRed, s)
+	}
+	return s
+}
+
+// Options are the presentation settings shared by all formatters.
+type Options struct {
+	Locale locale.Locale
+	Theme  Theme
+}
+
+// DefaultOptions formats for en-US with Unicode borders and no colors.
+var DefaultOptions = Options{Locale: locale.EnUS, Theme: DefaultTheme}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package output
+
+import "os"
+
+func ttyWidth(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,20 @@
+//go:build linux || darwin
+
+package output
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+func ttyWidth(f *os.File) int {
+	var ws struct {
+		rows, cols, xpixel, ypixel uint16
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0
+	}
+	return int(ws.cols)
+}
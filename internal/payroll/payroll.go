@@ -0,0 +1,273 @@
+// Package payroll aggregates the converted salaries of a team.
+package payroll
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"salary-calc/internal/converter"
+)
+
+// Row is one line of the payroll file: a salary paid to Headcount people,
+// costing Overhead times the salary when fully loaded.
+type Row struct {
+	Line      int
+	Input     converter.Input
+	Headcount float64
+	Overhead  float64
+	// Fields holds every column of the line by lower-case header name.
+	Fields map[string]string
+}
+
+// Read parses a payroll CSV file. It needs amount and currency columns, and
+// may have period (default: month), headcount (default: 1) and overhead
+// columns; rows without an overhead use the given one. Other columns are
+// kept for grouping.
+func Read(r io.Reader, overhead float64) ([]Row, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payroll file: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("payroll file needs a header and at least one row")
+	}
+
+	header := make([]string, len(records[0]))
+	for i, name := range records[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+	for _, required := range []string{"amount", "currency"} {
+		if !contains(header, required) {
+			return nil, fmt.Errorf("payroll file has no %s column", required)
+		}
+	}
+
+	rows := make([]Row, 0, len(records)-1)
+	for i, record := range records[1:] {
+		line := i + 2
+		fields := make(map[string]string, len(header))
+		for j, name := range header {
+			fields[name] = strings.TrimSpace(record[j])
+		}
+
+		row, err := parseRow(fields, overhead)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseRow(fields map[string]string, overhead float64) (Row, error) {
+	amount, err := strconv.ParseFloat(fields["amount"], 64)
+	if err != nil || amount < 0 {
+		return Row{}, fmt.Errorf("invalid amount: %q", fields["amount"])
+	}
+
+	name := strings.TrimSpace(fields["currency"])
+	if name == "" {
+		return Row{}, fmt.Errorf("missing currency")
+	}
+	currency, err := converter.ValidateCurrency(strings.ToUpper(name))
+	if err != nil {
+		return Row{}, err
+	}
+
+	period := converter.PeriodMonth
+	if name := fields["period"]; name != "" {
+		if period, err = converter.ValidatePeriod(strings.ToLower(name)); err != nil {
+			return Row{}, err
+		}
+	}
+
+	row := Row{
+		Input:     converter.Input{Amount: amount, Period: period, Currency: currency},
+		Headcount: 1,
+		Overhead:  overhead,
+		Fields:    fields,
+	}
+	if value := fields["headcount"]; value != "" {
+		if row.Headcount, err = strconv.ParseFloat(value, 64); err != nil || row.Headcount <= 0 {
+			return Row{}, fmt.Errorf("invalid headcount: %q", value)
+		}
+	}
+	if value := fields["overhead"]; value != "" {
+		if row.Overhead, err = strconv.ParseFloat(value, 64); err != nil || row.Overhead <= 0 {
+			return Row{}, fmt.Errorf("invalid overhead: %q", value)
+		}
+	}
+	return row, nil
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Amounts holds a value per period and reporting currency.
+type Amounts map[converter.Period]map[converter.Currency]float64
+
+func (a Amounts) add(period converter.Period, currency converter.Currency, value float64) {
+	if a[period] == nil {
+		a[period] = make(map[converter.Currency]float64)
+	}
+	a[period][currency] += value
+}
+
+// Stats aggregates a set of rows. Mean and Median are per person, weighting
+// each row by its headcount; Loaded is the total including overhead.
+type Stats struct {
+	Headcount float64 `json:"headcount"`
+	Total     Amounts `json:"total"`
+	Loaded    Amounts `json:"loaded"`
+	Mean      Amounts `json:"mean"`
+	Median    Amounts `json:"median"`
+}
+
+// Group is the Stats of the rows sharing a value in the grouping column.
+type Group struct {
+	Name string `json:"name"`
+	Stats
+}
+
+// Summary is the aggregated payroll.
+type Summary struct {
+	Rows       int                  `json:"rows"`
+	Currencies []converter.Currency `json:"currencies"`
+	// HasOverhead reports whether any row costs more than its salary.
+	HasOverhead bool    `json:"has_overhead"`
+	All         Stats   `json:"all"`
+	GroupBy     string  `json:"group_by,omitempty"`
+	Groups      []Group `json:"groups,omitempty"`
+}
+
+// Summarize converts every row with conv into the reporting currencies and
+// aggregates them, overall and by the groupBy column when set.
+func Summarize(rows []Row, conv *converter.Converter, currencies []converter.Currency, groupBy string) (*Summary, error) {
+	groupBy = strings.ToLower(groupBy)
+	if groupBy != "" && len(rows) > 0 {
+		if _, ok := rows[0].Fields[groupBy]; !ok {
+			return nil, fmt.Errorf("payroll file has no %s column", groupBy)
+		}
+	}
+
+	all := newAccumulator()
+	groups := make(map[string]*accumulator)
+	summary := &Summary{Rows: len(rows), Currencies: currencies, GroupBy: groupBy}
+
+	for _, row := range rows {
+		results, err := conv.Convert(row.Input)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", row.Line, err)
+		}
+		if row.Overhead != 1 {
+			summary.HasOverhead = true
+		}
+
+		all.add(row, results, currencies)
+		if groupBy != "" {
+			name := row.Fields[groupBy]
+			if groups[name] == nil {
+				groups[name] = newAccumulator()
+			}
+			groups[name].add(row, results, currencies)
+		}
+	}
+
+	summary.All = all.stats()
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		summary.Groups = append(summary.Groups, Group{Name: name, Stats: groups[name].stats()})
+	}
+	return summary, nil
+}
+
+type weighted struct {
+	value, weight float64
+}
+
+type accumulator struct {
+	headcount float64
+	total     Amounts
+	loaded    Amounts
+	values    map[converter.Period]map[converter.Currency][]weighted
+}
+
+func newAccumulator() *accumulator {
+	return &accumulator{
+		total:  make(Amounts),
+		loaded: make(Amounts),
+		values: make(map[converter.Period]map[converter.Currency][]weighted),
+	}
+}
+
+func (a *accumulator) add(row Row, results map[converter.Period]map[converter.Currency]float64, currencies []converter.Currency) {
+	a.headcount += row.Headcount
+	for _, period := range converter.ValidPeriods {
+		if a.values[period] == nil {
+			a.values[period] = make(map[converter.Currency][]weighted)
+		}
+		for _, currency := range currencies {
+			value := results[period][currency]
+			a.total.add(period, currency, value*row.Headcount)
+			a.loaded.add(period, currency, value*row.Headcount*row.Overhead)
+			a.values[period][currency] = append(a.values[period][currency], weighted{value, row.Headcount})
+		}
+	}
+}
+
+func (a *accumulator) stats() Stats {
+	stats := Stats{
+		Headcount: a.headcount,
+		Total:     a.total,
+		Loaded:    a.loaded,
+		Mean:      make(Amounts),
+		Median:    make(Amounts),
+	}
+	for period, byCurrency := range a.values {
+		for currency, values := range byCurrency {
+			stats.Mean.add(period, currency, a.total[period][currency]/a.headcount)
+			stats.Median.add(period, currency, weightedMedian(values))
+		}
+	}
+	return stats
+}
+
+// weightedMedian returns the value below and above which half of the total
+// weight lies, averaging the two middle values when the halves meet exactly
+// between them.
+func weightedMedian(values []weighted) float64 {
+	sorted := append([]weighted(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].value < sorted[j].value })
+
+	var total float64
+	for _, v := range sorted {
+		total += v.weight
+	}
+
+	var cumulative float64
+	for i, v := range sorted {
+		cumulative += v.weight
+		if cumulative > total/2 {
+			return v.value
+		}
+		if cumulative == total/2 && i+1 < len(sorted) {
+			return (v.value + sorted[i+1].value) / 2
+		}
+	}
+	return sorted[len(sorted)-1].value
+}
@@ -0,0 +1,153 @@
+package payroll
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"salary-calc/internal/converter"
+)
+
+func TestWeightedMedian(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []weighted
+		want   float64
+	}{
+		{"single", []weighted{{100, 1}}, 100},
+		{"odd count", []weighted{{300, 1}, {100, 1}, {200, 1}}, 200},
+		{"tie between middle values", []weighted{{100, 1}, {200, 1}, {300, 1}, {400, 1}}, 250},
+		{"heavy row", []weighted{{100, 1}, {200, 3}}, 200},
+		{"tie with weights", []weighted{{100, 2}, {300, 1}, {500, 1}}, 200},
+		{"fractional headcount", []weighted{{100, 0.5}, {200, 0.5}, {300, 0.25}}, 200},
+		{"fractional tie", []weighted{{100, 0.5}, {300, 0.5}}, 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weightedMedian(tt.values); got != tt.want {
+				t.Errorf("weightedMedian() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	rows, err := Read(strings.NewReader(`name,Department,amount,currency,period,headcount,overhead
+Ana,Engineering,5000,eur,,1,1.3
+Bo,Engineering,30,USD,hour,2.5,
+Cy,Sales,18000,PLN,Month,,
+`), 1.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+
+	tests := []struct {
+		row       Row
+		currency  converter.Currency
+		period    converter.Period
+		headcount float64
+		overhead  float64
+		line      int
+	}{
+		{rows[0], converter.CurrencyEUR, converter.PeriodMonth, 1, 1.3, 2},
+		{rows[1], converter.CurrencyUSD, converter.PeriodHour, 2.5, 1.1, 3},
+		{rows[2], converter.CurrencyPLN, converter.PeriodMonth, 1, 1.1, 4},
+	}
+	for _, tt := range tests {
+		r := tt.row
+		if r.Input.Currency != tt.currency || r.Input.Period != tt.period ||
+			r.Headcount != tt.headcount || r.Overhead != tt.overhead || r.Line != tt.line {
+			t.Errorf("line %d: got %s %s headcount %v overhead %v, want %s %s %v %v (line %d)",
+				r.Line, r.Input.Currency, r.Input.Period, r.Headcount, r.Overhead,
+				tt.currency, tt.period, tt.headcount, tt.overhead, tt.line)
+		}
+	}
+	if rows[2].Fields["department"] != "Sales" {
+		t.Errorf("department = %q, want Sales", rows[2].Fields["department"])
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name, csv, want string
+	}{
+		{"no amount column", "currency\nEUR\n", "no amount column"},
+		{"no rows", "amount,currency\n", "at least one row"},
+		{"negative amount", "amount,currency\n-1,EUR\n", "line 2: invalid amount"},
+		{"unknown currency", "amount,currency\n1,CHF\n", "line 2"},
+		{"empty currency", "amount,currency\n1,EUR\n2,\n", "line 3: missing currency"},
+		{"no currency column", "amount\n1\n", "no currency column"},
+		{"zero headcount", "amount,currency,headcount\n1,EUR,0\n", "invalid headcount"},
+		{"bad overhead", "amount,currency,overhead\n1,EUR,x\n", "invalid overhead"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.csv), 1)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Read() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	rows, err := Read(strings.NewReader(`department,amount,currency,headcount,overhead
+Engineering,4000,EUR,1,1.5
+Engineering,20000,PLN,0.5,
+Sales,10000,PLN,2,
+`), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conv := converter.NewConverter(map[string]float64{"EUR": 1, "PLN": 4, "USD": 1.1, "GBP": 0.85}, "EUR")
+	summary, err := Summarize(rows, conv, []converter.Currency{converter.CurrencyPLN}, "Department")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	month := func(a Amounts) float64 { return a[converter.PeriodMonth][converter.CurrencyPLN] }
+	approx := func(name string, got, want float64) {
+		t.Helper()
+		if math.Abs(got-want) > 1e-6 {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+
+	if !summary.HasOverhead {
+		t.Error("HasOverhead not set although a row has overhead 1.5")
+	}
+	approx("headcount", summary.All.Headcount, 3.5)
+	// 16000 + 0.5*20000 + 2*10000 PLN.
+	approx("total", month(summary.All.Total), 46000)
+	// Only the first row has overhead: 16000*1.5 + 10000 + 20000.
+	approx("loaded", month(summary.All.Loaded), 54000)
+	approx("mean", month(summary.All.Mean), 46000/3.5)
+	// Weights 2 (10000), 1 (16000), 0.5 (20000): the median is 10000.
+	approx("median", month(summary.All.Median), 10000)
+
+	if len(summary.Groups) != 2 || summary.Groups[0].Name != "Engineering" || summary.Groups[1].Name != "Sales" {
+		t.Fatalf("groups = %+v, want Engineering and Sales", summary.Groups)
+	}
+	engineering := summary.Groups[0]
+	approx("engineering headcount", engineering.Headcount, 1.5)
+	approx("engineering total", month(engineering.Total), 26000)
+	// 16000 weighs 1 and 20000 weighs 0.5, so 16000 holds more than half.
+	approx("engineering median", month(engineering.Median), 16000)
+}
+
+func TestSummarizeUnknownGroupColumn(t *testing.T) {
+	rows, err := Read(strings.NewReader("amount,currency\n1,EUR\n"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conv := converter.NewConverter(map[string]float64{"EUR": 1, "PLN": 4, "USD": 1.1, "GBP": 0.85}, "EUR")
+	if _, err := Summarize(rows, conv, converter.ValidCurrencies, "team"); err == nil || !strings.Contains(err.Error(), "no team column") {
+		t.Errorf("Summarize() error = %v, want no team column", err)
+	}
+}
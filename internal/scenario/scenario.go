@@ -0,0 +1,217 @@
+// Package scenario shows how salary costs in a budget currency respond to
+// exchange rate moves.
+package scenario
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"salary-calc/internal/converter"
+	"salary-calc/internal/exchangerate"
+	"salary-calc/internal/payroll"
+)
+
+// ParseShocks parses a comma-separated list of percentage moves such as
+// "±5%,+10%,-2.5". A ± (or +-) prefix adds both signs. The result is sorted,
+// without duplicates, and always includes 0. Moves of -100% or less are
+// rejected, as they would make a rate zero or negative.
+func ParseShocks(s string) ([]float64, error) {
+	seen := map[float64]bool{0: true}
+	for _, raw := range strings.Split(s, ",") {
+		raw = strings.TrimSpace(raw)
+		item := strings.TrimSuffix(raw, "%")
+		if item == "" {
+			continue
+		}
+
+		both := false
+		for _, prefix := range []string{"±", "+-"} {
+			if strings.HasPrefix(item, prefix) {
+				item, both = strings.TrimPrefix(item, prefix), true
+			}
+		}
+
+		pct, err := strconv.ParseFloat(item, 64)
+		if err != nil || pct <= -100 || (both && pct >= 100) || math.IsInf(pct, 0) {
+			return nil, fmt.Errorf("invalid shock: %q (expected e.g. ±5%%, +10%%, -2.5%%)", raw)
+		}
+		seen[pct] = true
+		if both {
+			seen[-pct] = true
+		}
+	}
+
+	shocks := make([]float64, 0, len(seen))
+	for pct := range seen {
+		shocks = append(shocks, pct)
+	}
+	sort.Float64s(shocks)
+	return shocks, nil
+}
+
+// Shock returns rates quoted against base in which every other currency is
+// pct percent more expensive in budget terms, i.e. the budget currency has
+// weakened by pct against all of them.
+func Shock(rates map[string]float64, base, budget string, pct float64) map[string]float64 {
+	factor := 1 + pct/100
+	shocked := make(map[string]float64, len(rates))
+	for currency, rate := range rates {
+		switch {
+		case budget != base && currency == budget:
+			shocked[currency] = rate * factor
+		case budget == base && currency != base:
+			shocked[currency] = rate / factor
+		default:
+			shocked[currency] = rate
+		}
+	}
+	return shocked
+}
+
+// Row is the budget cost of the salaries for each period under one shock.
+type Row struct {
+	Shock float64                      `json:"shock"`
+	Cost  map[converter.Period]float64 `json:"cost"`
+}
+
+// Exposure is the part of the salaries paid in one foreign currency and how
+// much its budget cost moves per 1% change of that currency.
+type Exposure struct {
+	Currency   converter.Currency `json:"currency"`
+	Amount     float64            `json:"amount"`
+	Rate       float64            `json:"rate"`
+	Cost       float64            `json:"cost"`
+	PerPercent float64            `json:"per_percent"`
+}
+
+// Range is the budget cost range implied by the historical volatility of
+// one currency against the budget currency.
+type Range struct {
+	Currency converter.Currency `json:"currency"`
+	Samples  int                `json:"samples"`
+	// DailyVolatility is the standard deviation of daily log returns, in
+	// percent.
+	DailyVolatility float64 `json:"daily_volatility"`
+	Low             float64 `json:"low"`
+	High            float64 `json:"high"`
+	CostLow         float64 `json:"cost_low"`
+	CostHigh        float64 `json:"cost_high"`
+}
+
+// Report is a complete scenario analysis.
+type Report struct {
+	Budget    converter.Currency `json:"budget"`
+	Period    converter.Period   `json:"period"`
+	Rows      []Row              `json:"matrix"`
+	Exposures []Exposure         `json:"sensitivity"`
+	// Ranges, Lookback and Horizon are set when a volatility range was
+	// requested.
+	Ranges   []Range `json:"volatility,omitempty"`
+	Lookback string  `json:"lookback,omitempty"`
+	Horizon  string  `json:"horizon,omitempty"`
+}
+
+// Build computes the shocked cost matrix and the sensitivity of the budget
+// cost of salaries for period. rates are quoted against base.
+func Build(salaries []payroll.Row, rates map[string]float64, base string, budget converter.Currency, period converter.Period, shocks []float64) (*Report, error) {
+	report := &Report{Budget: budget, Period: period}
+
+	for _, pct := range shocks {
+		conv := converter.NewConverter(Shock(rates, base, string(budget), pct), base)
+		row := Row{Shock: pct, Cost: make(map[converter.Period]float64)}
+		for _, salary := range salaries {
+			results, err := conv.Convert(salary.Input)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range converter.ValidPeriods {
+				row.Cost[p] += results[p][budget] * salary.Headcount * salary.Overhead
+			}
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	conv := converter.NewConverter(rates, base)
+	byCurrency := make(map[converter.Currency]*Exposure)
+	for _, salary := range salaries {
+		currency := salary.Input.Currency
+		if currency == budget {
+			continue
+		}
+
+		results, err := conv.Convert(salary.Input)
+		if err != nil {
+			return nil, err
+		}
+		rate, err := conv.Rate(currency, budget)
+		if err != nil {
+			return nil, err
+		}
+
+		e := byCurrency[currency]
+		if e == nil {
+			e = &Exposure{Currency: currency, Rate: rate}
+			byCurrency[currency] = e
+		}
+		weight := salary.Headcount * salary.Overhead
+		e.Amount += results[period][currency] * weight
+		e.Cost += results[period][budget] * weight
+	}
+
+	for _, currency := range converter.ValidCurrencies {
+		if e := byCurrency[currency]; e != nil {
+			e.PerPercent = e.Cost / 100
+			report.Exposures = append(report.Exposures, *e)
+		}
+	}
+	return report, nil
+}
+
+// z95 is the two-sided 95% quantile of the normal distribution.
+const z95 = 1.96
+
+// VolatilityRange estimates the 95% range of exposure's rate and budget cost
+// after horizonDays days from the daily log returns of points, the rate
+// history of the exposure currency against the budget currency. Returns
+// between non-consecutive days are scaled to one day.
+func VolatilityRange(exposure Exposure, points []exchangerate.Point, horizonDays float64) (Range, error) {
+	var returns []float64
+	for i := 1; i < len(points); i++ {
+		days := points[i].Date.Sub(points[i-1].Date).Hours() / 24
+		if days <= 0 || points[i].Rate <= 0 || points[i-1].Rate <= 0 {
+			continue
+		}
+		returns = append(returns, math.Log(points[i].Rate/points[i-1].Rate)/math.Sqrt(days))
+	}
+	if len(returns) < 2 {
+		return Range{}, fmt.Errorf("not enough %s rate history for a volatility range (%d days)", exposure.Currency, len(points))
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	sigma := math.Sqrt(variance / float64(len(returns)-1))
+
+	spread := z95 * sigma * math.Sqrt(horizonDays)
+	low := exposure.Rate * math.Exp(-spread)
+	high := exposure.Rate * math.Exp(spread)
+	return Range{
+		Currency:        exposure.Currency,
+		Samples:         len(points),
+		DailyVolatility: sigma * 100,
+		Low:             low,
+		High:            high,
+		CostLow:         exposure.Amount * low,
+		CostHigh:        exposure.Amount * high,
+	}, nil
+}
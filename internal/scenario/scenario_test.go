@@ -0,0 +1,179 @@
+package scenario
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"salary-calc/internal/converter"
+	"salary-calc/internal/exchangerate"
+)
+
+func TestParseShocks(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []float64
+	}{
+		{"±5%,±10%", []float64{-10, -5, 0, 5, 10}},
+		{"+-2.5", []float64{-2.5, 0, 2.5}},
+		{"+10%, 10, -3", []float64{-3, 0, 10}},
+		{"0%", []float64{0}},
+		{"", []float64{0}},
+		{"±99.5%", []float64{-99.5, 0, 99.5}},
+		{"+150%", []float64{0, 150}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseShocks(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseShocks(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseShocksErrors(t *testing.T) {
+	// A mirrored move of 100% or more includes -100% or less, which would
+	// make a rate zero or negative.
+	for _, in := range []string{"abc", "5%%", "-100%", "-150", "±100%", "+-150", "±inf"} {
+		if got, err := ParseShocks(in); err == nil || !strings.Contains(err.Error(), "invalid shock") {
+			t.Errorf("ParseShocks(%q) = %v, %v; want an invalid shock error", in, got, err)
+		}
+	}
+}
+
+func TestShock(t *testing.T) {
+	rates := map[string]float64{"EUR": 1, "PLN": 4.25, "USD": 1.1}
+
+	tests := []struct {
+		name   string
+		budget string
+		want   map[string]float64
+	}{
+		{
+			// PLN weakens: one EUR buys 10% more PLN, the others are unchanged.
+			name:   "budget is not the base",
+			budget: "PLN",
+			want:   map[string]float64{"EUR": 1, "PLN": 4.25 * 1.1, "USD": 1.1},
+		},
+		{
+			// EUR weakens: one EUR buys 10% less of every other currency.
+			name:   "budget is the base",
+			budget: "EUR",
+			want:   map[string]float64{"EUR": 1, "PLN": 4.25 / 1.1, "USD": 1.1 / 1.1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Shock(rates, "EUR", tt.budget, 10)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Shock() = %v, want %v", got, tt.want)
+			}
+			for currency, want := range tt.want {
+				if math.Abs(got[currency]-want) > 1e-9 {
+					t.Errorf("Shock()[%s] = %v, want %v", currency, got[currency], want)
+				}
+			}
+
+			// Every other currency costs 10% more in the budget currency.
+			before := converter.NewConverter(rates, "EUR")
+			after := converter.NewConverter(got, "EUR")
+			for currency := range rates {
+				if currency == tt.budget {
+					continue
+				}
+				r0, _ := before.Rate(converter.Currency(currency), converter.Currency(tt.budget))
+				r1, _ := after.Rate(converter.Currency(currency), converter.Currency(tt.budget))
+				if math.Abs(r1/r0-1.1) > 1e-9 {
+					t.Errorf("%s in %s moved by %v, want 1.1", currency, tt.budget, r1/r0)
+				}
+			}
+		})
+	}
+
+	if rates["PLN"] != 4.25 || rates["USD"] != 1.1 {
+		t.Errorf("Shock() modified its input: %v", rates)
+	}
+}
+
+func TestVolatilityRange(t *testing.T) {
+	day := func(n int) time.Time { return time.Date(2026, 10, 1+n, 0, 0, 0, 0, time.UTC) }
+	exposure := Exposure{Currency: converter.CurrencyPLN, Amount: 1000, Rate: 4}
+
+	// Log returns of 0.01 and 0.02 a day: the second over a four-day gap,
+	// 0.04 in total, scales to 0.02 a day.
+	points := []exchangerate.Point{
+		{Date: day(0), Rate: 1},
+		{Date: day(1), Rate: math.Exp(0.01)},
+		{Date: day(5), Rate: math.Exp(0.05)},
+	}
+	got, err := VolatilityRange(exposure, points, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigma := math.Sqrt(0.00005) // sample deviation of 0.01 and 0.02
+	spread := 1.96 * sigma * 2  // over sqrt(4) days
+	want := Range{
+		Currency:        converter.CurrencyPLN,
+		Samples:         3,
+		DailyVolatility: sigma * 100,
+		Low:             4 * math.Exp(-spread),
+		High:            4 * math.Exp(spread),
+		CostLow:         4000 * math.Exp(-spread),
+		CostHigh:        4000 * math.Exp(spread),
+	}
+	approx := func(name string, got, want float64) {
+		t.Helper()
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	if got.Currency != want.Currency || got.Samples != want.Samples {
+		t.Errorf("currency %s, samples %d; want %s, %d", got.Currency, got.Samples, want.Currency, want.Samples)
+	}
+	approx("daily volatility", got.DailyVolatility, want.DailyVolatility)
+	approx("low", got.Low, want.Low)
+	approx("high", got.High, want.High)
+	approx("cost low", got.CostLow, want.CostLow)
+	approx("cost high", got.CostHigh, want.CostHigh)
+}
+
+func TestVolatilityRangeConstantRate(t *testing.T) {
+	points := []exchangerate.Point{
+		{Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Rate: 4.25},
+		{Date: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Rate: 4.25},
+		{Date: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), Rate: 4.25},
+	}
+	got, err := VolatilityRange(Exposure{Currency: converter.CurrencyPLN, Amount: 100, Rate: 4.25}, points, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DailyVolatility != 0 || got.Low != 4.25 || got.High != 4.25 || got.CostLow != 425 || got.CostHigh != 425 {
+		t.Errorf("VolatilityRange() = %+v, want no spread", got)
+	}
+}
+
+func TestVolatilityRangeNeedsHistory(t *testing.T) {
+	exposure := Exposure{Currency: converter.CurrencyUSD, Amount: 100, Rate: 1.1}
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, points := range [][]exchangerate.Point{
+		nil,
+		{{Date: day, Rate: 1.1}},
+		{{Date: day, Rate: 1.1}, {Date: day.AddDate(0, 0, 1), Rate: 1.2}},
+		// A repeated day gives no return.
+		{{Date: day, Rate: 1.1}, {Date: day, Rate: 1.2}, {Date: day.AddDate(0, 0, 1), Rate: 1.3}},
+	} {
+		if _, err := VolatilityRange(exposure, points, 30); err == nil || !strings.Contains(err.Error(), "not enough USD rate history") {
+			t.Errorf("VolatilityRange(%d points) error = %v, want not enough history", len(points), err)
+		}
+	}
+}